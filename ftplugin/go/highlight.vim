@@ -10,3 +10,23 @@ highlight delveCallStackSign   guibg=#394d39  guibg=None
 highlight GoCoverMiss          guifg=#5f0000  guibg=None
 highlight GoCoverPartial       guifg=#f0c674  guibg=None
 highlight GoCoverHit           guifg=#a0a85c  guibg=None
+
+highlight GoCoverHeat1         guifg=#808080  guibg=None
+highlight GoCoverHeat2         guifg=#748c83  guibg=None
+highlight GoCoverHeat3         guifg=#689886  guibg=None
+highlight GoCoverHeat4         guifg=#5ca489  guibg=None
+highlight GoCoverHeat5         guifg=#50b08c  guibg=None
+highlight GoCoverHeat6         guifg=#44bc8f  guibg=None
+highlight GoCoverHeat7         guifg=#38c892  guibg=None
+highlight GoCoverHeat8         guifg=#2cd495  guibg=None
+highlight GoCoverHeat9         guifg=#20e098  guibg=None
+highlight GoCoverHeat10        guifg=#14ec9b  guibg=None
+highlight default link GoCoverCount Comment
+
+highlight default link GoSameId Search
+
+highlight default link GoDiagnosticError   SpellBad
+highlight default link GoDiagnosticMessage WarningMsg
+
+highlight GoTestPassSign       guifg=#a0a85c  guibg=None
+highlight GoTestFailSign       guifg=#cc1100  guibg=None
@@ -2,7 +2,13 @@
 nnoremap <silent><Plug>(nvim-go-analyzeview)  :<C-u>GoAnalyzeView<CR>
 
 " GoBuild
-nnoremap <silent><Plug>(nvim-go-build)  :<C-u>Gobuild<CR>
+nnoremap <silent><Plug>(nvim-go-build)         :<C-u>Gobuild<CR>
+nnoremap <silent><Plug>(nvim-go-build-matrix)  :<C-u>GoBuildMatrix<CR>
+nnoremap <silent><Plug>(nvim-go-install)       :<C-u>GoInstall<CR>
+
+" GoDecls
+nnoremap <silent><Plug>(nvim-go-decls)      :<C-u>GoDecls<CR>
+nnoremap <silent><Plug>(nvim-go-decls-dir)  :<C-u>GoDeclsDir<CR>
 
 " GoGenerate
 nnoremap <silent><Plug>(nvim-go-generatetest)   :<C-u>GoGenerateTest<CR>
@@ -10,9 +16,16 @@ nnoremap <silent><Plug>(nvim-go-generatetest)   :<C-u>GoGenerateTest<CR>
 " GoGuru
 nnoremap <silent><Plug>(nvim-go-callees)       :<C-u>call GoGuru('callees')<CR>
 nnoremap <silent><Plug>(nvim-go-callers)       :<C-u>call GoGuru('callers')<CR>
+nnoremap <silent><Plug>(nvim-go-call-hierarchy) :<C-u>GoCallHierarchy<CR>
+nnoremap <silent><Plug>(nvim-go-callgraph)     :<C-u>GoCallgraph<CR>
 nnoremap <silent><Plug>(nvim-go-callstack)     :<C-u>call GoGuru('callstack')<CR>
 nnoremap <silent><Plug>(nvim-go-definition)    :<C-u>call GoGuru('definition')<CR>
+nnoremap <silent><Plug>(nvim-go-def-pop)       :<C-u>GoDefPop<CR>
+nnoremap <silent><Plug>(nvim-go-def-stack)     :<C-u>GoDefStack<CR>
+nnoremap <silent><Plug>(nvim-go-sameids)       :<C-u>GoSameIds<CR>
+nnoremap <silent><Plug>(nvim-go-sameids-clear) :<C-u>GoSameIdsClear<CR>
 nnoremap <silent><Plug>(nvim-go-describe)      :<C-u>call GoGuru('describe')<CR>
+nnoremap <silent><Plug>(nvim-go-info)          :<C-u>GoInfo<CR>
 nnoremap <silent><Plug>(nvim-go-freevars)      :<C-u>call GoGuru('freevars')<CR>
 nnoremap <silent><Plug>(nvim-go-implements)    :<C-u>call GoGuru('implements')<CR>
 nnoremap <silent><Plug>(nvim-go-channelpeers)  :<C-u>call GoGuru('peers')<CR>
@@ -23,14 +36,26 @@ nnoremap <silent><Plug>(nvim-go-whicherrs)     :<C-u>call GoGuru('whicherrs')<CR
 " GoIferr
 nnoremap <silent><Plug>(nvim-go-iferr)  :<C-u>GoIferr<CR>
 
+" GoCover
+nnoremap <silent><Plug>(nvim-go-cover)         :<C-u>GoCover<CR>
+nnoremap <silent><Plug>(nvim-go-cover-clear)   :<C-u>GoCoverClear<CR>
+nnoremap <silent><Plug>(nvim-go-cover-toggle)  :<C-u>GoCoverToggle<CR>
+nnoremap <silent><Plug>(nvim-go-cover-report)  :<C-u>GoCoverReport<CR>
+nnoremap <silent><Plug>(nvim-go-cover-html)    :<C-u>GoCoverHTML<CR>
+
 " GoLint
 nnoremap <silent><Plug>(nvim-go-lint)  :<C-u>Golint<CR>
 
 " GoMetaLinker
 nnoremap <silent><Plug>(nvim-go-metalinter)  :<C-u>Gometalinter<CR>
 
+" GoBench
+nnoremap <silent><Plug>(nvim-go-bench)  :<C-u>GoBench<CR>
+
 " GoTest
 nnoremap <silent><Plug>(nvim-go-test)         :<C-u>Gotest<CR>
+nnoremap <silent><Plug>(nvim-go-test-func)    :<C-u>GoTestFunc<CR>
+nnoremap <silent><Plug>(nvim-go-test-results) :<C-u>GoTestResults<CR>
 nnoremap <silent><Plug>(nvim-go-switch-test)  :<C-u>GoSwitchTest<CR>
 
 " GoRename
@@ -38,10 +38,10 @@ endfunction
 " plugin manifest
 call remote#host#Register(s:plugin_name, '*', function('s:RequireNvimGo'))
 call remote#host#RegisterPlugin('nvim-go', '0', [
-\ {'type': 'autocmd', 'name': 'BufEnter', 'sync': 1, 'opts': {'eval': '{''BufNr'': bufnr(''%''), ''WinID'': win_getid(), ''Dir'': expand(''%:p:h'')}', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'BufEnter', 'sync': 1, 'opts': {'eval': '{''BufNr'': bufnr(''%''), ''WinID'': win_getid(), ''File'': expand(''%:p''), ''Dir'': expand(''%:p:h'')}', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'BufWritePre', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]', 'group': 'nvim-go', 'pattern': '*.go'}},
-\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'eval': '{''Global'': {''ServerName'': v:servername, ''ErrorListType'': get(g:, ''go#global#errorlisttype'', ''locationlist'')}, ''Build'': {''Appengine'': get(g:, ''go#build#appengine'', 0), ''Autosave'': get(g:, ''go#build#autosave'', 0), ''Force'': get(g:, ''go#build#force'', 0), ''Flags'': get(g:, ''go#build#flags'', []), ''IsNotGb'': get(g:, ''go#build#is_not_gb'', 0)}, ''Cover'': {''Flags'': get(g:, ''go#cover#flags'', []), ''Mode'': get(g:, ''go#cover#mode'', '''')}, ''Fmt'': {''Autosave'': get(g:, ''go#fmt#autosave'', 0), ''Mode'': get(g:, ''go#fmt#mode'', ''goimports'')}, ''Generate'': {''TestAllFuncs'': get(g:, ''go#generate#test#allfuncs'', 1), ''TestExclFuncs'': get(g:, ''go#generate#test#exclude'', ''''), ''TestExportedFuncs'': get(g:, ''go#generate#test#exportedfuncs'', 0), ''TestSubTest'': get(g:, ''go#generate#test#subtest'', 1)}, ''Guru'': {''Reflection'': get(g:, ''go#guru#reflection'', 0), ''KeepCursor'': get(g:, ''go#guru#keep_cursor'', {''callees'':0,''callers'':0,''callstack'':0,''definition'':0,''describe'':0,''freevars'':0,''implements'':0,''peers'':0,''pointsto'':0,''referrers'':0,''whicherrs'':0}), ''JumpFirst'': get(g:, ''go#guru#jump_first'', 0)}, ''Iferr'': {''Autosave'': get(g:, ''go#iferr#autosave'', 0)}, ''Lint'': {''GolintAutosave'': get(g:, ''go#lint#golint#autosave'', 0), ''GolintIgnore'': get(g:, ''go#lint#golint#ignore'', []), ''GolintMinConfidence'': get(g:, ''go#lint#golint#min_confidence'', 0.8), ''GolintMode'': get(g:, ''go#lint#golint#mode'', ''current''), ''GoVetAutosave'': get(g:, ''go#lint#govet#autosave'', 0), ''GoVetFlags'': get(g:, ''go#lint#govet#flags'', []), ''GoVetIgnore'': get(g:, ''go#lint#govet#ignore'', []), ''MetalinterAutosave'': get(g:, ''go#lint#metalinter#autosave'', 0), ''MetalinterAutosaveTools'': get(g:, ''go#lint#metalinter#autosave#tools'', [''vet'', ''golint'']), ''MetalinterTools'': get(g:, ''go#lint#metalinter#tools'', [''vet'', ''golint'']), ''MetalinterDeadline'': get(g:, ''go#lint#metalinter#deadline'', ''5s''), ''MetalinterSkipDir'': get(g:, ''go#lint#metalinter#skip_dir'', [])}, ''Rename'': {''Prefill'': get(g:, ''go#rename#prefill'', 0)}, ''Terminal'': {''Mode'': get(g:, ''go#terminal#mode'', ''vsplit''), ''Position'': get(g:, ''go#terminal#position'', ''belowright''), ''Height'': get(g:, ''go#terminal#height'', 0), ''Width'': get(g:, ''go#terminal#width'', 0), ''StopInsert'': get(g:, ''go#terminal#stop_insert'', 1)}, ''Test'': {''AllPackage'': get(g:, ''go#test#all_package'', 0), ''Autosave'': get(g:, ''go#test#autosave'', 0), ''Flags'': get(g:, ''go#test#flags'', [])}, ''Debug'': {''Enable'': get(g:, ''go#debug'', 0), ''Pprof'': get(g:, ''go#debug#pprof'', 0)}}', 'group': 'nvim-go', 'pattern': '*.go'}},
+\ {'type': 'autocmd', 'name': 'VimEnter', 'sync': 0, 'opts': {'eval': '{''Global'': {''ServerName'': v:servername, ''ErrorListType'': get(g:, ''go#global#errorlisttype'', ''locationlist'')}, ''Build'': {''Appengine'': get(g:, ''go#build#appengine'', 0), ''Autosave'': get(g:, ''go#build#autosave'', 0), ''AutosaveMode'': get(g:, ''go#build#autosave#mode'', ''build''), ''Force'': get(g:, ''go#build#force'', 0), ''Flags'': get(g:, ''go#build#flags'', []), ''Tags'': get(g:, ''go#build#tags'', []), ''Targets'': get(g:, ''go#build#targets'', []), ''IsNotGb'': get(g:, ''go#build#is_not_gb'', 0)}, ''Bench'': {''Count'': get(g:, ''go#bench#count'', 5), ''Flags'': get(g:, ''go#bench#flags'', [])}, ''Cover'': {''Flags'': get(g:, ''go#cover#flags'', []), ''Mode'': get(g:, ''go#cover#mode'', ''''), ''DiffBase'': get(g:, ''go#cover#diff_base'', ''HEAD''), ''Count'': get(g:, ''go#cover#count'', 0)}, ''Diagnostics'': {''Enable'': get(g:, ''go#diagnostics#enable'', 0), ''Delay'': get(g:, ''go#diagnostics#delay'', 500)}, ''Fmt'': {''Autosave'': get(g:, ''go#fmt#autosave'', 0), ''Mode'': get(g:, ''go#fmt#mode'', ''goimports'')}, ''Generate'': {''TestAllFuncs'': get(g:, ''go#generate#test#allfuncs'', 1), ''TestExclFuncs'': get(g:, ''go#generate#test#exclude'', ''''), ''TestExportedFuncs'': get(g:, ''go#generate#test#exportedfuncs'', 0), ''TestSubTest'': get(g:, ''go#generate#test#subtest'', 1)}, ''Guru'': {''Reflection'': get(g:, ''go#guru#reflection'', 0), ''KeepCursor'': get(g:, ''go#guru#keep_cursor'', {''callees'':0,''callers'':0,''callstack'':0,''definition'':0,''describe'':0,''freevars'':0,''implements'':0,''peers'':0,''pointsto'':0,''referrers'':0,''whicherrs'':0}), ''JumpFirst'': get(g:, ''go#guru#jump_first'', 0), ''SameIdsAuto'': get(g:, ''go#guru#sameids#auto'', 0), ''Cache'': get(g:, ''go#guru#cache'', 1), ''InfoAuto'': get(g:, ''go#guru#info#auto'', 0), ''CallgraphAlgo'': get(g:, ''go#guru#callgraph#algo'', ''static''), ''CallgraphDepth'': get(g:, ''go#guru#callgraph#depth'', 3), ''CallgraphDot'': get(g:, ''go#guru#callgraph#dot'', ''callgraph.dot'')}, ''Iferr'': {''Autosave'': get(g:, ''go#iferr#autosave'', 0)}, ''Lint'': {''GolintAutosave'': get(g:, ''go#lint#golint#autosave'', 0), ''GolintIgnore'': get(g:, ''go#lint#golint#ignore'', []), ''GolintMinConfidence'': get(g:, ''go#lint#golint#min_confidence'', 0.8), ''GolintMode'': get(g:, ''go#lint#golint#mode'', ''current''), ''GoVetAutosave'': get(g:, ''go#lint#govet#autosave'', 0), ''GoVetFlags'': get(g:, ''go#lint#govet#flags'', []), ''GoVetIgnore'': get(g:, ''go#lint#govet#ignore'', []), ''MetalinterAutosave'': get(g:, ''go#lint#metalinter#autosave'', 0), ''MetalinterAutosaveTools'': get(g:, ''go#lint#metalinter#autosave#tools'', [''vet'', ''golint'']), ''MetalinterTools'': get(g:, ''go#lint#metalinter#tools'', [''vet'', ''golint'']), ''MetalinterDeadline'': get(g:, ''go#lint#metalinter#deadline'', ''5s''), ''MetalinterSkipDir'': get(g:, ''go#lint#metalinter#skip_dir'', [])}, ''Rename'': {''Prefill'': get(g:, ''go#rename#prefill'', 0)}, ''Terminal'': {''Mode'': get(g:, ''go#terminal#mode'', ''vsplit''), ''Position'': get(g:, ''go#terminal#position'', ''belowright''), ''Height'': get(g:, ''go#terminal#height'', 0), ''Width'': get(g:, ''go#terminal#width'', 0), ''StopInsert'': get(g:, ''go#terminal#stop_insert'', 1)}, ''Test'': {''AllPackage'': get(g:, ''go#test#all_package'', 0), ''Autosave'': get(g:, ''go#test#autosave'', 0), ''Flags'': get(g:, ''go#test#flags'', []), ''JSON'': get(g:, ''go#test#json'', 0)}, ''Debug'': {''Enable'': get(g:, ''go#debug'', 0), ''Pprof'': get(g:, ''go#debug#pprof'', 0)}}', 'group': 'nvim-go', 'pattern': '*.go'}},
 \ {'type': 'autocmd', 'name': 'VimLeavePre', 'sync': 0, 'opts': {'group': 'nvim-go', 'pattern': '*.go,terminal,context,thread'}},
 \ {'type': 'command', 'name': 'DlvBreakpoint', 'sync': 0, 'opts': {'complete': 'customlist,FunctionsCompletion', 'eval': '[expand(''%:p'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'DlvConnect', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
@@ -52,13 +52,36 @@ call remote#host#RegisterPlugin('nvim-go', '0', [
 \ {'type': 'command', 'name': 'DlvRestart', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvState', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'DlvStdin', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoBench', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'GoBuffers', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'GoBuildMatrix', 'sync': 0, 'opts': {'complete': 'customlist,GoTargetCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoBuildTags', 'sync': 0, 'opts': {'bang': '', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'GoByteOffset', 'sync': 1, 'opts': {'eval': 'expand(''%:p'')', 'range': '%'}},
-\ {'type': 'command', 'name': 'GoCover', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoCallHierarchy', 'sync': 0, 'opts': {'complete': 'customlist,GoCallHierarchyCompletion', 'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2), bufnr(''%'')]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoCallgraph', 'sync': 0, 'opts': {'complete': 'customlist,GoCallgraphCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoCover', 'sync': 0, 'opts': {'complete': 'file', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoCoverClear', 'sync': 0, 'opts': {}},
+\ {'type': 'command', 'name': 'GoCoverHTML', 'sync': 0, 'opts': {'bang': '', 'complete': 'file', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoCoverReport', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoCoverSave', 'sync': 0, 'opts': {'complete': 'file', 'eval': 'getcwd()', 'nargs': '1'}},
+\ {'type': 'command', 'name': 'GoCoverToggle', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p'')]'}},
+\ {'type': 'command', 'name': 'GoDecls', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p''), &modified, bufnr(''%'')]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoDeclsDir', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p''), &modified, bufnr(''%'')]', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoDefPop', 'sync': 0, 'opts': {'eval': 'win_getid()', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'GoDefStack', 'sync': 0, 'opts': {'eval': 'win_getid()'}},
+\ {'type': 'command', 'name': 'GoDefStackClear', 'sync': 0, 'opts': {'eval': 'win_getid()'}},
 \ {'type': 'command', 'name': 'GoGenerateTest', 'sync': 0, 'opts': {'addr': 'line', 'bang': '', 'complete': 'file', 'eval': 'expand(''%:p:h'')', 'nargs': '*', 'range': '%'}},
+\ {'type': 'command', 'name': 'GoGuruScope', 'sync': 0, 'opts': {'bang': '', 'complete': 'customlist,GoGuruScopeCompletion', 'eval': '[getcwd(), expand(''%:p:h'')]', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'GoIferr', 'sync': 0, 'opts': {'eval': 'expand(''%:p'')'}},
+\ {'type': 'command', 'name': 'GoInfo', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2), bufnr(''%'')]'}},
+\ {'type': 'command', 'name': 'GoInstall', 'sync': 0, 'opts': {'bang': '', 'complete': 'customlist,GoInstallCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoSameIds', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2), bufnr(''%'')]'}},
+\ {'type': 'command', 'name': 'GoSameIdsClear', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'GoSwitchTest', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]'}},
 \ {'type': 'command', 'name': 'GoTabpages', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'GoTarget', 'sync': 0, 'opts': {'bang': '', 'complete': 'customlist,GoTargetCompletion', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoTestFunc', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), line2byte(line(''.'')) + (col(''.'')-2)]', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'GoTestResults', 'sync': 0, 'opts': {}},
 \ {'type': 'command', 'name': 'GoWindows', 'sync': 1, 'opts': {}},
 \ {'type': 'command', 'name': 'Gobuild', 'sync': 0, 'opts': {'bang': '', 'eval': '[getcwd(), expand(''%:p'')]'}},
 \ {'type': 'command', 'name': 'Gofmt', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')'}},
@@ -70,8 +93,13 @@ call remote#host#RegisterPlugin('nvim-go', '0', [
 \ {'type': 'command', 'name': 'Gotest', 'sync': 0, 'opts': {'eval': 'expand(''%:p:h'')', 'nargs': '*'}},
 \ {'type': 'command', 'name': 'Govet', 'sync': 0, 'opts': {'complete': 'customlist,GoVetCompletion', 'eval': '[getcwd(), expand(''%:p'')]', 'nargs': '*'}},
 \ {'type': 'function', 'name': 'FunctionsCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'GoCallHierarchyCompletion', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'GoCallgraphCompletion', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'GoGuru', 'sync': 0, 'opts': {'eval': '[getcwd(), expand(''%:p''), &modified, line2byte(line(''.'')) + (col(''.'')-2)]'}},
+\ {'type': 'function', 'name': 'GoGuruScopeCompletion', 'sync': 1, 'opts': {'eval': 'expand(''%:p:h'')'}},
+\ {'type': 'function', 'name': 'GoInstallCompletion', 'sync': 1, 'opts': {'eval': 'expand(''%:p:h'')'}},
 \ {'type': 'function', 'name': 'GoLintCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
+\ {'type': 'function', 'name': 'GoTargetCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
 \ {'type': 'function', 'name': 'GoVetCompletion', 'sync': 1, 'opts': {'eval': 'getcwd()'}},
 \ ])
 
@@ -26,6 +26,10 @@ type Autocmd struct {
 	mu               sync.Mutex
 	wg               sync.WaitGroup
 
+	// for the live diagnostics debounce and cancellation of each buffer
+	diagMu sync.Mutex
+	diags  map[int]*diagnostics
+
 	errs *syncmap.Map
 }
 
@@ -37,6 +41,7 @@ func Register(p *plugin.Plugin, ctx *ctx.Context, cmd *command.Command) {
 		cmd:              cmd,
 		bufWritePreChan:  make(chan interface{}),
 		bufWritePostChan: make(chan error),
+		diags:            make(map[int]*diagnostics),
 		errs:             new(syncmap.Map),
 	}
 
@@ -44,4 +49,9 @@ func Register(p *plugin.Plugin, ctx *ctx.Context, cmd *command.Command) {
 	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePost", Pattern: "*.go", Group: "nvim-go", Eval: "[getcwd(), expand('%:p')]"}, autocmd.bufWritePost)
 	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePre", Pattern: "*.go", Group: "nvim-go", Eval: "[getcwd(), expand('%:p')]"}, autocmd.bufWritePre)
 	p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimEnter", Pattern: "*.go", Group: "nvim-go", Eval: "*"}, autocmd.VimEnter)
+
+	// RPC export
+	p.Handle("GoDiagnostics", autocmd.TextChanged)            // live diagnostics on TextChanged, TextChangedI and InsertLeave
+	p.Handle("GoDiagnosticsBufDelete", autocmd.diagBufDelete) // cancels the live diagnostics of the deleted buffer
+	p.Handle("GoCursorHold", autocmd.cursorHold)              // GoSameIds and GoInfo on CursorHold
 }
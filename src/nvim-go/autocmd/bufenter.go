@@ -4,10 +4,13 @@
 
 package autocmd
 
-// bufEnterEval represents the current buffer number, windows ID and buffer files directory.
+import "nvim-go/nvimutil"
+
+// bufEnterEval represents the current buffer number, windows ID, buffer file and files directory.
 type bufEnterEval struct {
 	BufNr int    `eval:"bufnr('%')"`
 	WinID int    `eval:"win_getid()"`
+	File  string `eval:"expand('%:p')"`
 	Dir   string `eval:"expand('%:p:h')"`
 }
 
@@ -19,5 +22,13 @@ func (a *Autocmd) BufEnter(eval *bufEnterEval) error {
 	a.mu.Unlock()
 
 	a.ctx.SetContext(eval.Dir)
+
+	// reapply the coverage highlights if the buffer is the profiled package file
+	go func() {
+		if err := a.cmd.CoverBufEnter(eval.BufNr, eval.File); err != nil {
+			nvimutil.ErrorWrap(a.Nvim, err)
+		}
+	}()
+
 	return nil
 }
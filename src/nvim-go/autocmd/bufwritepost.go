@@ -27,6 +27,9 @@ func (a *Autocmd) bufWritePost(eval *bufWritePostEval) {
 func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 	dir := filepath.Dir(eval.File)
 
+	// the saved file outdates the cached guru analysis program
+	a.cmd.ReloadGuruCache(eval.File)
+
 	if config.FmtAutosave {
 		err := <-a.bufWritePreChan
 		switch e := err.(type) {
@@ -40,10 +43,16 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 	}
 
 	if config.BuildAutosave {
-		err := a.cmd.Build(config.BuildForce, &command.CmdBuildEval{
+		buildEval := &command.CmdBuildEval{
 			Cwd:  eval.Cwd,
 			File: eval.File,
-		})
+		}
+		var err interface{}
+		if config.BuildAutosaveMode == "typecheck" {
+			err = a.cmd.TypeCheck(buildEval)
+		} else {
+			err = a.cmd.Build(config.BuildForce, buildEval)
+		}
 		switch e := err.(type) {
 		case error:
 			return nvimutil.ErrorWrap(a.Nvim, e)
@@ -104,7 +113,14 @@ func (a *Autocmd) BufWritePost(eval *bufWritePostEval) error {
 		a.wg.Add(1)
 		go func() {
 			defer a.wg.Done()
-			a.cmd.Test(nil, dir)
+			a.errs.Delete("Test")
+			err := a.cmd.Test(nil, dir)
+			switch e := err.(type) {
+			case error:
+				nvimutil.ErrorWrap(a.Nvim, e)
+			case []*nvim.QuickfixError:
+				a.errs.Store("Test", e)
+			}
 		}()
 	}
 
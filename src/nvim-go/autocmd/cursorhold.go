@@ -0,0 +1,56 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"fmt"
+
+	"nvim-go/command"
+	"nvim-go/config"
+	"nvim-go/log"
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+// cursorHoldAugroup augroup name of the CursorHold autocmd.
+const cursorHoldAugroup = "nvim-go-cursorhold"
+
+// registerCursorHold defines the CursorHold autocmd if config.GuruSameIdsAuto or
+// config.GuruInfoAuto. This is not the static plugin autocmd, for avoid the
+// rpc notification of each CursorHold when both are disabled.
+func (a *Autocmd) registerCursorHold() error {
+	if !config.GuruSameIdsAuto && !config.GuruInfoAuto {
+		return nil
+	}
+
+	batch := a.Nvim.NewBatch()
+	batch.Command("augroup " + cursorHoldAugroup)
+	batch.Command("autocmd!")
+	batch.Command(fmt.Sprintf("autocmd CursorHold *.go call rpcnotify(%d, 'GoCursorHold', [getcwd(), expand('%%:p'), &modified, line2byte(line('.')) + (col('.')-2), bufnr('%%')])", config.ChannelID))
+	batch.Command("augroup END")
+
+	return errors.WithStack(batch.Execute())
+}
+
+func (a *Autocmd) cursorHold(eval *command.CmdSameIdsEval) {
+	go a.CursorHold(eval)
+}
+
+// CursorHold run the commands on CursorHold autocmd.
+func (a *Autocmd) CursorHold(eval *command.CmdSameIdsEval) {
+	if config.GuruSameIdsAuto {
+		if err := a.cmd.SameIds(eval); err != nil {
+			nvimutil.ErrorWrap(a.Nvim, err)
+		}
+	}
+	if config.GuruInfoAuto {
+		// stay silent on CursorHold such as the cursor on the broken code,
+		// the error is shown by the explicit GoInfo
+		if err := a.cmd.Info((*command.CmdInfoEval)(eval)); err != nil {
+			log.Printf("GoInfo: %+v", err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nvim-go/command"
+	"nvim-go/config"
+	"nvim-go/log"
+
+	"github.com/pkg/errors"
+)
+
+// diagnosticsAugroup augroup name of the live diagnostics autocmds.
+const diagnosticsAugroup = "nvim-go-diagnostics"
+
+// registerDiagnostics defines the live diagnostics autocmds if config.DiagnosticsEnable.
+// These are not the static plugin autocmds, for avoid the rpc notification of
+// each keystroke when disabled.
+func (a *Autocmd) registerDiagnostics() error {
+	if !config.DiagnosticsEnable {
+		return nil
+	}
+
+	batch := a.Nvim.NewBatch()
+	batch.Command("augroup " + diagnosticsAugroup)
+	batch.Command("autocmd!")
+	batch.Command(fmt.Sprintf("autocmd TextChanged,TextChangedI,InsertLeave *.go call rpcnotify(%d, 'GoDiagnostics', [getcwd(), expand('%%:p'), bufnr('%%')])", config.ChannelID))
+	batch.Command(fmt.Sprintf("autocmd BufDelete *.go call rpcnotify(%d, 'GoDiagnosticsBufDelete', str2nr(expand('<abuf>')))", config.ChannelID))
+	batch.Command("augroup END")
+
+	return errors.WithStack(batch.Execute())
+}
+
+// diagnostics represents the pending or in-flight live diagnostics of a buffer.
+type diagnostics struct {
+	timer  *time.Timer
+	cancel context.CancelFunc
+}
+
+// stop stops the pending diagnostics and cancels the in-flight one.
+func (d *diagnostics) stop() {
+	d.timer.Stop()
+	d.cancel()
+}
+
+// TextChanged runs the live diagnostics on TextChanged, TextChangedI and
+// InsertLeave autocmds. The diagnostics is debounced by config.DiagnosticsDelay
+// for each buffer, and the pending or in-flight diagnostics of the buffer is
+// canceled by the newer edit of the same buffer.
+// The errors are logged instead of echoed, because it runs in the background
+// on each edit.
+func (a *Autocmd) TextChanged(eval *command.CmdDiagnosticsEval) {
+	if !config.DiagnosticsEnable {
+		return
+	}
+
+	a.diagMu.Lock()
+	defer a.diagMu.Unlock()
+
+	if d, ok := a.diags[eval.BufNr]; ok {
+		d.stop()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &diagnostics{cancel: cancel}
+	d.timer = time.AfterFunc(time.Duration(config.DiagnosticsDelay)*time.Millisecond, func() {
+		defer a.diagDone(eval.BufNr, d)
+		if err := a.cmd.Diagnostics(ctx, eval); err != nil {
+			log.Printf("GoDiagnostics: %+v", err)
+		}
+	})
+	a.diags[eval.BufNr] = d
+}
+
+// diagDone releases the finished diagnostics d of the bufnr buffer.
+func (a *Autocmd) diagDone(bufnr int, d *diagnostics) {
+	d.cancel()
+
+	a.diagMu.Lock()
+	defer a.diagMu.Unlock()
+	if a.diags[bufnr] == d {
+		delete(a.diags, bufnr)
+	}
+}
+
+// diagBufDelete cancels and releases the live diagnostics of the deleted bufnr buffer.
+func (a *Autocmd) diagBufDelete(bufnr int) {
+	a.diagMu.Lock()
+	defer a.diagMu.Unlock()
+
+	if d, ok := a.diags[bufnr]; ok {
+		d.stop()
+		delete(a.diags, bufnr)
+	}
+}
@@ -7,6 +7,7 @@ package autocmd
 import (
 	"nvim-go/config"
 	"nvim-go/log"
+	"nvim-go/nvimutil"
 )
 
 // VimEnter gets user config variables and assign to global variable when autocmd VimEnter.
@@ -14,6 +15,12 @@ func (a *Autocmd) VimEnter(cfg *config.Config) {
 	cfg.Global.ChannelID = a.Nvim.ChannelID()
 
 	config.Get(a.Nvim, cfg)
+	if err := a.registerDiagnostics(); err != nil {
+		nvimutil.ErrorWrap(a.Nvim, err)
+	}
+	if err := a.registerCursorHold(); err != nil {
+		nvimutil.ErrorWrap(a.Nvim, err)
+	}
 	cfg2, err := config.Read()
 	if err != nil {
 		log.Debugln(err)
@@ -0,0 +1,509 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoBench
+
+// cmdBenchEval struct type for Eval of GoBench command.
+type cmdBenchEval struct {
+	Cwd    string `msgpack:",array"`
+	File   string
+	Offset int
+}
+
+func (c *Command) cmdBench(args []string, eval *cmdBenchEval) {
+	go func() {
+		c.errs.Delete("Bench")
+
+		err := c.Bench(args, eval)
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Bench", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// benchBuf scratch buffer of the benchmark results.
+var benchBuf = newScratchBuffer("__GO_BENCH__", nvimutil.FiletypeGoBench, "botright split")
+
+// Bench runs the benchmarks of the current package, or the benchmark function
+// of the current cursor position, and compares the results with the previous
+// saved run.
+func (c *Command) Bench(args []string, eval *cmdBenchEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoBench")
+
+	dir := filepath.Dir(eval.File)
+	flags := []string{"-run", "^$", "-bench", "."}
+	if strings.HasSuffix(eval.File, testSuffix) {
+		buf, err := c.Nvim.BufferLines(nvim.Buffer(c.ctx.BufNr), 0, -1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fset := token.NewFileSet()
+		if f := parse(eval.File, fset, nvimutil.ToByteSlice(buf)); f != nil {
+			offset := fset.File(f.Pos()).Pos(eval.Offset)
+			if fn := testFuncFlags(f, offset); len(fn) == 4 && fn[2] == "-bench" {
+				flags = fn
+			}
+		}
+	}
+
+	pkgID, err := pathutil.PackageID(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the -count 0 runs no benchmarks
+	count := config.BenchCount
+	if count < 1 {
+		count = 1
+	}
+
+	cmd := exec.Command(c.ctx.Build.Compiler(), "test")
+	cmd.Args = append(cmd.Args, buildTagsFlag()...)
+	cmd.Args = append(cmd.Args, flags...)
+	cmd.Args = append(cmd.Args, "-benchmem", "-count", strconv.FormatInt(count, 10))
+	cmd.Args = append(cmd.Args, config.BenchFlags...)
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Args = append(cmd.Args, pkgID)
+	cmd.Dir = c.testDir(dir)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	nvimutil.EchoProgress(c.Nvim, "GoBench", "running %s", strings.Join(flags, " "))
+	if benchErr := cmd.Run(); benchErr != nil {
+		if _, ok := benchErr.(*exec.ExitError); !ok {
+			return errors.WithStack(benchErr)
+		}
+		errlist, err := nvimutil.ParseError(append(stdout.Bytes(), stderr.Bytes()...), eval.Cwd, &c.ctx.Build, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(errlist) > 0 {
+			return errlist
+		}
+		return errors.Errorf("GoBench: %s", strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+
+	run := parseBench(stdout.Bytes())
+	if len(run.Names) == 0 {
+		return nvimutil.Echoerr(c.Nvim, "GoBench: no benchmark results")
+	}
+	run.Package = pkgID
+	run.Commit = benchCommit(dir)
+	run.Time = time.Now()
+
+	benchDir := filepath.Join(config.ConfigHome, "bench", filepath.FromSlash(pkgID))
+	prev, err := loadLatestBench(benchDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := saveBench(benchDir, run); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := benchBuf.open(c, map[string]string{"q": ":<C-u>quit<CR>"}); err != nil {
+		return err
+	}
+	return benchBuf.setLines(c, formatBench(prev, run))
+}
+
+// benchSample represents a result line of the benchmark.
+type benchSample struct {
+	N           int
+	NsPerOp     float64
+	BytesPerOp  float64
+	AllocsPerOp float64
+	// HasMem whether the sample has the -benchmem results.
+	HasMem bool
+}
+
+// benchRun represents the benchmark results of a run.
+type benchRun struct {
+	Package string
+	Commit  string
+	Time    time.Time
+	// Names benchmark names in order of appearance.
+	Names   []string
+	Samples map[string][]benchSample
+}
+
+// benchLineRe matches the benchmark result line such as
+// "BenchmarkFoo-8   1000000   1234 ns/op   128 B/op   2 allocs/op".
+var benchLineRe = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+(.+)$`)
+
+// parseBench parses the go test -bench output.
+func parseBench(out []byte) *benchRun {
+	run := &benchRun{Samples: make(map[string][]benchSample)}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		m := benchLineRe.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+
+		sample := benchSample{N: n}
+		fields := strings.Fields(m[3])
+		for i := 0; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				sample.NsPerOp = v
+			case "B/op":
+				sample.BytesPerOp = v
+				sample.HasMem = true
+			case "allocs/op":
+				sample.AllocsPerOp = v
+				sample.HasMem = true
+			}
+		}
+
+		name := m[1]
+		if _, ok := run.Samples[name]; !ok {
+			run.Names = append(run.Names, name)
+		}
+		run.Samples[name] = append(run.Samples[name], sample)
+	}
+
+	return run
+}
+
+// benchCommit returns the current commit hash of the dir repository.
+func benchCommit(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = pathutil.FindVCSRoot(dir)
+	out, err := cmd.Output()
+	if err != nil {
+		return "nocommit"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// loadLatestBench loads the latest saved benchmark results in dir by the run time.
+// Returns nil if there is no saved results.
+func loadLatestBench(dir string) (*benchRun, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var latest *benchRun
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		run := new(benchRun)
+		if err := json.Unmarshal(data, run); err != nil {
+			continue
+		}
+		if latest == nil || run.Time.After(latest.Time) {
+			latest = run
+		}
+	}
+
+	return latest, nil
+}
+
+// saveBench saves the run results to dir. The file name is the run time and
+// the commit hash, so the runs on the same commit are kept such as the
+// uncommitted changes.
+func saveBench(dir string, run *benchRun) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, benchFileName(run)), data, 0600)
+}
+
+// benchFileName returns the saved file name of run such as "20170102T150405.000000000-abc1234.json".
+func benchFileName(run *benchRun) string {
+	return fmt.Sprintf("%s-%s.json", run.Time.UTC().Format("20060102T150405.000000000"), run.Commit)
+}
+
+// benchStats represents the statistics of the benchmark metric values.
+type benchStats struct {
+	Values []float64
+	Mean   float64
+	// Diff maximum deviation from the mean in percent.
+	Diff float64
+}
+
+func newBenchStats(values []float64) *benchStats {
+	s := &benchStats{Values: values}
+	if len(values) == 0 {
+		return s
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		s.Mean += v
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	s.Mean /= float64(len(values))
+	if s.Mean != 0 {
+		s.Diff = math.Max(max-s.Mean, s.Mean-min) / s.Mean * 100
+	}
+
+	return s
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U-test of x and y
+// use the normal approximation with the tie correction.
+// Returns false if the samples are too small.
+func mannWhitneyU(x, y []float64) (float64, bool) {
+	n1, n2 := len(x), len(y)
+	if n1 < 2 || n2 < 2 {
+		return 0, false
+	}
+
+	type value struct {
+		v   float64
+		isX bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, value{v, true})
+	}
+	for _, v := range y {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// assigns the average rank to the ties
+	var r1, tie float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].isX {
+				r1 += rank
+			}
+		}
+		t := float64(j - i)
+		tie += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := r1 - float64(n1*(n1+1))/2
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tie/(n*(n-1))))
+	if sigma == 0 {
+		return 1, true
+	}
+
+	z := (math.Abs(u-mu) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2), true
+}
+
+// benchAlpha significance level of the comparison.
+const benchAlpha = 0.05
+
+// benchMetric represents a metric of the benchmark result.
+type benchMetric struct {
+	name   string
+	value  func(benchSample) float64
+	format func(float64) string
+	mem    bool
+}
+
+var benchMetrics = []benchMetric{
+	{name: "time/op", value: func(s benchSample) float64 { return s.NsPerOp }, format: formatBenchTime},
+	{name: "alloc/op", value: func(s benchSample) float64 { return s.BytesPerOp }, format: formatBenchBytes, mem: true},
+	{name: "allocs/op", value: func(s benchSample) float64 { return s.AllocsPerOp }, format: formatBenchNumber, mem: true},
+}
+
+// formatBench formats the run results table like benchstat, and compares with
+// the prev results if not nil.
+func formatBench(prev, run *benchRun) []string {
+	var buf bytes.Buffer
+	if prev != nil {
+		fmt.Fprintf(&buf, "GoBench: %s  old: %s (%s)  new: %s (%s)\n", run.Package,
+			prev.Commit, prev.Time.Format("2006-01-02 15:04:05"), run.Commit, run.Time.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Fprintf(&buf, "GoBench: %s  %s (%s)\n", run.Package, run.Commit, run.Time.Format("2006-01-02 15:04:05"))
+	}
+
+	hasMem := false
+	for _, samples := range run.Samples {
+		for _, s := range samples {
+			hasMem = hasMem || s.HasMem
+		}
+	}
+
+	for _, metric := range benchMetrics {
+		if metric.mem && !hasMem {
+			continue
+		}
+
+		buf.WriteString("\n")
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		if prev != nil {
+			fmt.Fprintf(w, "name\told %s\tnew %s\tdelta\t\n", metric.name, metric.name)
+		} else {
+			fmt.Fprintf(w, "name\t%s\t\n", metric.name)
+		}
+
+		for _, name := range run.Names {
+			cur := newBenchStats(benchValues(run.Samples[name], metric.value))
+			if prev == nil {
+				fmt.Fprintf(w, "%s\t%s\t\n", strings.TrimPrefix(name, "Benchmark"), formatBenchStats(cur, metric.format))
+				continue
+			}
+
+			old := newBenchStats(benchValues(prev.Samples[name], metric.value))
+			if len(old.Values) == 0 {
+				fmt.Fprintf(w, "%s\t\t%s\t\t\n", strings.TrimPrefix(name, "Benchmark"), formatBenchStats(cur, metric.format))
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", strings.TrimPrefix(name, "Benchmark"),
+				formatBenchStats(old, metric.format), formatBenchStats(cur, metric.format), benchDelta(old, cur))
+		}
+		w.Flush()
+	}
+
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+func benchValues(samples []benchSample, value func(benchSample) float64) []float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = value(s)
+	}
+	return values
+}
+
+// benchDelta returns the delta column of the comparison.
+// "~" means the difference is not statistically significant.
+func benchDelta(old, cur *benchStats) string {
+	if old.Mean == 0 && cur.Mean == 0 {
+		return "~ (all equal)"
+	}
+
+	p, ok := mannWhitneyU(old.Values, cur.Values)
+	n := fmt.Sprintf("n=%d+%d", len(old.Values), len(cur.Values))
+	if !ok {
+		return fmt.Sprintf("~ (%s)", n)
+	}
+	if p > benchAlpha || old.Mean == 0 {
+		return fmt.Sprintf("~ (p=%.3f %s)", p, n)
+	}
+	return fmt.Sprintf("%+.2f%% (p=%.3f %s)", (cur.Mean-old.Mean)/old.Mean*100, p, n)
+}
+
+func formatBenchStats(s *benchStats, format func(float64) string) string {
+	if len(s.Values) < 2 {
+		return format(s.Mean)
+	}
+	return fmt.Sprintf("%s \u00b1 %.0f%%", format(s.Mean), s.Diff) // \u00b1: ±
+}
+
+// formatBenchScaled formats v with 3 significant digits.
+func formatBenchScaled(v float64, unit string) string {
+	switch {
+	case v >= 100:
+		return fmt.Sprintf("%.0f%s", v, unit)
+	case v >= 10:
+		return fmt.Sprintf("%.1f%s", v, unit)
+	default:
+		return fmt.Sprintf("%.2f%s", v, unit)
+	}
+}
+
+func formatBenchTime(ns float64) string {
+	switch {
+	case ns >= 1e9:
+		return formatBenchScaled(ns/1e9, "s")
+	case ns >= 1e6:
+		return formatBenchScaled(ns/1e6, "ms")
+	case ns >= 1e3:
+		return formatBenchScaled(ns/1e3, "\u00b5s") // \u00b5: µ
+	default:
+		return formatBenchScaled(ns, "ns")
+	}
+}
+
+func formatBenchBytes(b float64) string {
+	switch {
+	case b >= 1e9:
+		return formatBenchScaled(b/1e9, "GB")
+	case b >= 1e6:
+		return formatBenchScaled(b/1e6, "MB")
+	case b >= 1e3:
+		return formatBenchScaled(b/1e3, "kB")
+	default:
+		return formatBenchScaled(b, "B")
+	}
+}
+
+func formatBenchNumber(n float64) string {
+	switch {
+	case n >= 1e9:
+		return formatBenchScaled(n/1e9, "G")
+	case n >= 1e6:
+		return formatBenchScaled(n/1e6, "M")
+	case n >= 1e3:
+		return formatBenchScaled(n/1e3, "k")
+	default:
+		return formatBenchScaled(n, "")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBenchOutput = `goos: linux
+goarch: amd64
+pkg: foo.org/foo
+BenchmarkFoo-8   	 1000000	      1000 ns/op	     128 B/op	       2 allocs/op
+BenchmarkFoo-8   	 1000000	      1100 ns/op	     128 B/op	       2 allocs/op
+BenchmarkBar/sub-8   	    2000	    900000 ns/op
+PASS
+ok  	foo.org/foo	3.456s
+`
+
+func TestParseBench(t *testing.T) {
+	run := parseBench([]byte(testBenchOutput))
+
+	if want := []string{"BenchmarkFoo-8", "BenchmarkBar/sub-8"}; !reflect.DeepEqual(run.Names, want) {
+		t.Errorf("parseBench() Names = %v, want %v", run.Names, want)
+	}
+	want := map[string][]benchSample{
+		"BenchmarkFoo-8": {
+			{N: 1000000, NsPerOp: 1000, BytesPerOp: 128, AllocsPerOp: 2, HasMem: true},
+			{N: 1000000, NsPerOp: 1100, BytesPerOp: 128, AllocsPerOp: 2, HasMem: true},
+		},
+		"BenchmarkBar/sub-8": {
+			{N: 2000, NsPerOp: 900000},
+		},
+	}
+	if !reflect.DeepEqual(run.Samples, want) {
+		t.Errorf("parseBench() Samples = %v, want %v", run.Samples, want)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   []float64
+		want   float64
+		wantOk bool
+	}{
+		{
+			name:   "significant",
+			x:      []float64{1, 2, 3, 4, 5},
+			y:      []float64{6, 7, 8, 9, 10},
+			want:   0.0122,
+			wantOk: true,
+		},
+		{
+			name:   "same distribution",
+			x:      []float64{1, 3, 5, 7, 9},
+			y:      []float64{2, 4, 6, 8, 10},
+			want:   0.6761,
+			wantOk: true,
+		},
+		{
+			name:   "all equal",
+			x:      []float64{1, 1, 1},
+			y:      []float64{1, 1, 1},
+			want:   1,
+			wantOk: true,
+		},
+		{
+			name:   "too small",
+			x:      []float64{1},
+			y:      []float64{2, 3},
+			want:   0,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := mannWhitneyU(tt.x, tt.y)
+			if ok != tt.wantOk {
+				t.Errorf("mannWhitneyU(%v, %v) ok = %v, want %v", tt.x, tt.y, ok, tt.wantOk)
+			}
+			if math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("mannWhitneyU(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBench(t *testing.T) {
+	prev := &benchRun{
+		Package: "foo.org/foo",
+		Commit:  "abc1234",
+		Time:    time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC),
+		Names:   []string{"BenchmarkFoo-8"},
+		Samples: map[string][]benchSample{
+			"BenchmarkFoo-8": {{NsPerOp: 2000}, {NsPerOp: 2100}, {NsPerOp: 2050}, {NsPerOp: 1990}, {NsPerOp: 2010}},
+		},
+	}
+	run := &benchRun{
+		Package: "foo.org/foo",
+		Commit:  "def5678",
+		Time:    time.Date(2017, 1, 3, 3, 4, 5, 0, time.UTC),
+		Names:   []string{"BenchmarkFoo-8", "BenchmarkBar-8"},
+		Samples: map[string][]benchSample{
+			"BenchmarkFoo-8": {{NsPerOp: 1000}, {NsPerOp: 1010}, {NsPerOp: 990}, {NsPerOp: 1000}, {NsPerOp: 1000}},
+			"BenchmarkBar-8": {{NsPerOp: 50}, {NsPerOp: 50}},
+		},
+	}
+
+	want := []string{
+		"GoBench: foo.org/foo  old: abc1234 (2017-01-02 03:04:05)  new: def5678 (2017-01-03 03:04:05)",
+		"",
+		"name   old time/op  new time/op  delta",
+		"Foo-8  2.03µs ± 3%  1.00µs ± 1%  -50.74% (p=0.011 n=5+5)",
+		"Bar-8               50.0ns ± 0%  ",
+	}
+	got := formatBench(prev, run)
+	for i := range got {
+		got[i] = strings.TrimRight(got[i], " ")
+	}
+	for i := range want {
+		want[i] = strings.TrimRight(want[i], " ")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formatBench() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSaveLoadBench(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-bench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if got, err := loadLatestBench(dir); err != nil || got != nil {
+		t.Fatalf("loadLatestBench() = %v, %v, want nil", got, err)
+	}
+
+	old := &benchRun{Commit: "abc1234", Time: time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC), Names: []string{"BenchmarkFoo"}}
+	prev := &benchRun{Commit: "def5678", Time: time.Date(2017, 1, 3, 0, 0, 0, 0, time.UTC), Names: []string{"BenchmarkFoo"}}
+	// the uncommitted changes on the same commit
+	cur := &benchRun{Commit: "def5678", Time: time.Date(2017, 1, 3, 1, 0, 0, 0, time.UTC), Names: []string{"BenchmarkBar"}}
+	for _, run := range []*benchRun{cur, old, prev} {
+		if err := saveBench(dir, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("saveBench() saved %d files, want %d", len(files), 3)
+	}
+
+	got, err := loadLatestBench(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Time.Equal(cur.Time) || !reflect.DeepEqual(got.Names, cur.Names) {
+		t.Errorf("loadLatestBench() = %v %v, want %v %v", got.Time, got.Names, cur.Time, cur.Names)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"nvim-go/nvimutil"
+
+	"github.com/pkg/errors"
+)
+
+// scratchBuffer represents a non-file buffer that shows the command results.
+type scratchBuffer struct {
+	*nvimutil.Buffer
+
+	name     string
+	filetype string
+	mode     string
+}
+
+// newScratchBuffer returns the scratchBuffer. The buffer is created lazily by open.
+func newScratchBuffer(name, filetype, mode string) *scratchBuffer {
+	return &scratchBuffer{
+		name:     name,
+		filetype: filetype,
+		mode:     mode,
+	}
+}
+
+// isValid reports whether the buffer is created and still valid.
+func (s *scratchBuffer) isValid(c *Command) bool {
+	return s.Buffer != nil && nvimutil.IsBufferValid(c.Nvim, s.Buffer.Buffer())
+}
+
+// open creates the buffer if not valid, and sets the nnoremap buffer local mappings.
+// Returns true if the buffer is newly created.
+func (s *scratchBuffer) open(c *Command, nnoremap map[string]string) (bool, error) {
+	if s.isValid(c) {
+		return false, nil
+	}
+
+	s.Buffer = nvimutil.NewBuffer(c.Nvim)
+	if err := s.Buffer.Create(s.name, s.filetype, s.mode, scratchBufferOption(s.filetype)); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if nnoremap != nil {
+		if err := s.Buffer.SetLocalMapping(nvimutil.NoremapNormal, nnoremap); err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+
+	return true, nil
+}
+
+// setLines replaces the all lines of the buffer.
+func (s *scratchBuffer) setLines(c *Command, lines []string) error {
+	buf := make([][]byte, len(lines))
+	for i, line := range lines {
+		buf[i] = []byte(line)
+	}
+
+	defer nvimutil.Modifiable(c.Nvim, s.Buffer.Buffer())()
+	return c.Nvim.SetBufferLines(s.Buffer.Buffer(), 0, -1, true, buf)
+}
+
+// scratchBufferOption returns the scratch buffer options.
+func scratchBufferOption(filetype string) map[nvimutil.NvimOption]map[string]interface{} {
+	option := make(map[nvimutil.NvimOption]map[string]interface{})
+	bufoption := make(map[string]interface{})
+	windowoption := make(map[string]interface{})
+
+	bufoption[nvimutil.BufOptionBufhidden] = nvimutil.BufhiddenDelete
+	bufoption[nvimutil.BufOptionBuflisted] = false
+	bufoption[nvimutil.BufOptionBuftype] = nvimutil.BuftypeNofile
+	bufoption[nvimutil.BufOptionFiletype] = filetype
+	bufoption[nvimutil.BufOptionModifiable] = false
+	bufoption[nvimutil.BufOptionSwapfile] = false
+
+	windowoption[nvimutil.WinOptionList] = false
+	windowoption[nvimutil.WinOptionNumber] = false
+	windowoption[nvimutil.WinOptionRelativenumber] = false
+
+	option[nvimutil.BufferOption] = bufoption
+	option[nvimutil.WindowOption] = windowoption
+
+	return option
+}
@@ -79,7 +79,13 @@ func (c *Command) Build(bang bool, eval *CmdBuildEval) interface{} {
 
 // compileCmd returns the *exec.Cmd corresponding to the compile tool.
 func (c *Command) compileCmd(bang bool, dir string) (*exec.Cmd, error) {
-	bin, err := exec.LookPath(c.ctx.Build.Tool)
+	return c.toolCmd("build", bang, dir)
+}
+
+// toolCmd returns the *exec.Cmd of the sub command such as "build" or "install"
+// corresponding to the compile tool. gb always uses the "build" sub command.
+func (c *Command) toolCmd(sub string, bang bool, dir string) (*exec.Cmd, error) {
+	bin, err := exec.LookPath(c.ctx.Build.Compiler())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -89,11 +95,15 @@ func (c *Command) compileCmd(bang bool, dir string) (*exec.Cmd, error) {
 		args = append(args, config.BuildFlags...)
 	}
 
-	cmd := exec.Command(bin, "build")
+	if c.ctx.Build.Tool == "gb" {
+		sub = "build"
+	}
+	cmd := exec.Command(bin, sub)
 	cmd.Dir = dir
+	args = append(args, buildTagsFlag()...)
 
 	switch c.ctx.Build.Tool {
-	case "go":
+	case "go", "mod":
 		// Outputs the binary to DevNull if without bang
 		if !bang {
 			args = append(args, "-o", os.DevNull)
@@ -117,6 +127,7 @@ func (c *Command) compileCmd(bang bool, dir string) (*exec.Cmd, error) {
 	}
 
 	cmd.Args = append(cmd.Args, args...)
+	cmd.Env = append(os.Environ(), c.ctx.Build.TargetEnv()...)
 
 	return cmd, nil
 }
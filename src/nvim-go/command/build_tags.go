@@ -0,0 +1,81 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/build"
+	"strings"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+)
+
+// buildTagsFlag returns the -tags flag of config.BuildTags for the go tools.
+// Returns nil if the build tags is not set.
+func buildTagsFlag() []string {
+	if len(config.BuildTags) == 0 {
+		return nil
+	}
+	return []string{"-tags", strings.Join(config.BuildTags, " ")}
+}
+
+// buildTagsContext returns a copy of the go/build.Default with config.BuildTags.
+func buildTagsContext() *build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = append(append([]string(nil), build.Default.BuildTags...), config.BuildTags...)
+	return &ctxt
+}
+
+// ----------------------------------------------------------------------------
+// GoBuildTags
+
+func (c *Command) cmdBuildTags(args []string, bang bool) {
+	go func() {
+		if err := c.BuildTags(args, bang); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// BuildTags sets the build tags to args at runtime, which overrides the
+// g:go#build#tags. Clears the build tags if bang is true, and shows the
+// current build tags if args is empty.
+func (c *Command) BuildTags(args []string, bang bool) error {
+	defer nvimutil.Profile(time.Now(), "GoBuildTags")
+
+	switch {
+	case bang:
+		config.BuildTags = nil
+		return nvimutil.Echomsg(c.Nvim, "GoBuildTags: cleared")
+
+	case len(args) == 0:
+		if len(config.BuildTags) == 0 {
+			return nvimutil.Echomsg(c.Nvim, "GoBuildTags: not set")
+		}
+		return nvimutil.Echomsg(c.Nvim, "GoBuildTags:", strings.Join(config.BuildTags, " "))
+	}
+
+	config.BuildTags = parseBuildTags(args)
+	return nvimutil.Echomsg(c.Nvim, "GoBuildTags:", strings.Join(config.BuildTags, " "))
+}
+
+// parseBuildTags splits the args to the build tags. Each arg may be a comma
+// separated list such as "integration,linux".
+func parseBuildTags(args []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		for _, tag := range strings.FieldsFunc(arg, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
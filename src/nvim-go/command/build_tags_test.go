@@ -0,0 +1,51 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBuildTags(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "space separated",
+			args: args{args: []string{"integration", "linux"}},
+			want: []string{"integration", "linux"},
+		},
+		{
+			name: "comma separated",
+			args: args{args: []string{"integration,linux", "appengine"}},
+			want: []string{"integration", "linux", "appengine"},
+		},
+		{
+			name: "duplicated",
+			args: args{args: []string{"linux", "integration,linux"}},
+			want: []string{"linux", "integration"},
+		},
+		{
+			name: "empty",
+			args: args{args: []string{","}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := parseBuildTags(tt.args.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBuildTags(%v) = %v, want %v", tt.args.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+	"golang.org/x/tools/go/buildutil"
+)
+
+// ----------------------------------------------------------------------------
+// GoCallHierarchy
+
+// CmdCallHierarchyEval struct type for Eval of GoCallHierarchy command.
+type CmdCallHierarchyEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	Offset   int
+	BufNr    int
+}
+
+// callNode represents a function node of the call hierarchy tree.
+type callNode struct {
+	// Name full name of the function such as "(*foo.T).Bar".
+	Name string
+	// Desc description of the call such as "static function call". Empty for the root.
+	Desc string
+	// Site position of the call site, or the function declaration of the root.
+	Site token.Position
+	// Query guru query position in the function for expand the node. Empty if
+	// the function has no source such as the synthetic wrapper.
+	Query string
+
+	Children []*callNode
+	// Loaded whether the Children are queried.
+	Loaded   bool
+	Expanded bool
+}
+
+// callHierarchy represents the incoming or outgoing call hierarchy tree of a function.
+type callHierarchy struct {
+	Root     *callNode
+	Outgoing bool
+	Scope    []string
+	Cwd      string
+	// overlay contents of the modified buffer at the time of GoCallHierarchy.
+	overlay map[string][]byte
+}
+
+var (
+	callHierarchyMu sync.Mutex
+	// lastCallHierarchy the call hierarchy tree of the buffer.
+	lastCallHierarchy *callHierarchy
+	// callHierarchyLines the node of each line of the buffer. The first line is the header.
+	callHierarchyLines []*callNode
+
+	callHierarchyBuf = newScratchBuffer("__GO_CALL_HIERARCHY__", nvimutil.FiletypeGoCallHierarchy, "botright split")
+)
+
+func (c *Command) cmdCallHierarchy(args []string, eval *CmdCallHierarchyEval) {
+	go func() {
+		if err := c.CallHierarchy(args, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// CallHierarchy opens the call hierarchy tree buffer of the function under
+// the cursor. The direction is incoming calls (callers) by default, or outgoing
+// calls (callees) if args[0] is "callees" or "outgoing".
+// Each node is expanded lazily by the guru query on the cached analysis program.
+// If the buffer is modified, the queries of the tree do not use the cache and
+// load the program with the buffer contents at the time of GoCallHierarchy
+// each time, because the cache is keyed by the scope without the overlay.
+func (c *Command) CallHierarchy(args []string, eval *CmdCallHierarchyEval) error {
+	defer nvimutil.Profile(time.Now(), "GoCallHierarchy")
+
+	var outgoing bool
+	if len(args) > 0 {
+		switch args[0] {
+		case "callers", "incoming":
+		case "callees", "outgoing":
+			outgoing = true
+		default:
+			return errors.Errorf("GoCallHierarchy: invalid direction: %s", args[0])
+		}
+	}
+
+	buf, err := c.Nvim.BufferLines(nvim.Buffer(eval.BufNr), 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	src := bytes.Join(buf, []byte{'\n'})
+
+	root, err := callHierarchyRoot(eval.File, src, eval.Offset)
+	if err != nil {
+		return err
+	}
+
+	scope, err := c.guruScope(eval.File)
+	if err != nil {
+		return err
+	}
+
+	h := &callHierarchy{
+		Root:     root,
+		Outgoing: outgoing,
+		Scope:    scope,
+		Cwd:      eval.Cwd,
+	}
+	if eval.Modified != 0 {
+		h.overlay = map[string][]byte{eval.File: src}
+	}
+
+	nvimutil.EchoProgress(c.Nvim, "GoCallHierarchy", "analysing %s", root.Name)
+	if err := h.expand(root); err != nil {
+		return err
+	}
+	defer nvimutil.ClearMsg(c.Nvim)
+
+	callHierarchyMu.Lock()
+	lastCallHierarchy = h
+	callHierarchyMu.Unlock()
+
+	// the actions may run the slow guru query, so notify instead of block the Neovim
+	rpc := func(action string) string {
+		return fmt.Sprintf(":<C-u>call rpcnotify(%d, 'GoCallHierarchyAction', '%s', line('.'))<CR>", config.ChannelID, action)
+	}
+	nnoremap := map[string]string{
+		"<CR>": rpc("jump"),
+		"o":    rpc("toggle"),
+		"d":    rpc("direction"),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := callHierarchyBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+
+	return c.refreshCallHierarchy()
+}
+
+// cmdCallHierarchyComplete completes the direction of the call hierarchy.
+func (c *Command) cmdCallHierarchyComplete(a *nvim.CommandCompletionArgs) ([]string, error) {
+	var candidates []string
+	for _, dir := range []string{"callees", "callers", "incoming", "outgoing"} {
+		if strings.HasPrefix(dir, a.ArgLead) {
+			candidates = append(candidates, dir)
+		}
+	}
+	return candidates, nil
+}
+
+// callHierarchyRoot returns the root node of the function declaration that
+// encloses offset in the src of file.
+func callHierarchyRoot(file string, src []byte, offset int) (*callNode, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	pos := fset.File(f.Pos()).Pos(offset)
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < fn.Pos() || pos > fn.End() {
+			continue
+		}
+		site := fset.Position(fn.Name.Pos())
+		return &callNode{
+			Name:  f.Name.Name + "." + funcDeclName(fn),
+			Site:  site,
+			Query: fmt.Sprintf("%s:#%d", file, site.Offset),
+		}, nil
+	}
+
+	return nil, errors.New("GoCallHierarchy: the cursor is not inside a function")
+}
+
+// context returns the build context of the guru query with the overlay.
+func (h *callHierarchy) context() *build.Context {
+	ctxt := buildTagsContext()
+	if h.overlay != nil {
+		ctxt = buildutil.OverlayContext(ctxt, h.overlay)
+	}
+	return ctxt
+}
+
+// readFile reads the file contents, or the overlay contents if exists.
+func (h *callHierarchy) readFile(filename string) ([]byte, error) {
+	if src, ok := h.overlay[filename]; ok {
+		return src, nil
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// expand queries the children of n if not loaded yet, and expands n.
+// The caller must not share h with the other goroutines.
+func (h *callHierarchy) expand(n *callNode) error {
+	if !n.Loaded {
+		children, err := h.query(n, h.Outgoing)
+		if err != nil {
+			return err
+		}
+		n.Children, n.Loaded = children, true
+	}
+	n.Expanded = true
+
+	return nil
+}
+
+// query runs the guru callers query of n, or the outgoing query if outgoing,
+// and returns the children nodes.
+func (h *callHierarchy) query(n *callNode, outgoing bool) ([]*callNode, error) {
+	if n.Query == "" {
+		return nil, nil
+	}
+
+	mode := "callers"
+	if outgoing {
+		mode = "outgoing"
+	}
+
+	var res interface{}
+	query := guru.Query{
+		Pos:        n.Query,
+		Build:      h.context(),
+		Scope:      h.Scope,
+		Reflection: config.GuruReflection,
+		Output: func(fset *token.FileSet, qr guru.QueryResult) {
+			res = qr.Result(fset)
+		},
+	}
+	if config.GuruCache {
+		query.Cache = guruCache
+	}
+	if err := guru.Run(mode, &query); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var children []*callNode
+	switch res := res.(type) {
+	case []serial.Caller:
+		for _, caller := range res {
+			// the call site is inside the caller function
+			site, q, ok := h.queryPos(caller.Pos)
+			if !ok {
+				continue
+			}
+			children = append(children, &callNode{Name: caller.Caller, Desc: caller.Desc, Site: site, Query: q})
+		}
+	case []*serial.Callees:
+		for _, callees := range res {
+			site, _, ok := h.queryPos(callees.Pos)
+			if !ok {
+				continue
+			}
+			for _, callee := range callees.Callees {
+				_, q, _ := h.queryPos(callee.Pos)
+				children = append(children, &callNode{Name: callee.Name, Desc: callees.Desc, Site: site, Query: q})
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// queryPos converts the "file:line:col" position of the guru result to the
+// token.Position and the guru query position.
+func (h *callHierarchy) queryPos(pos string) (token.Position, string, bool) {
+	filename, line, col, ok := splitDescribePos(pos)
+	if !ok {
+		return token.Position{}, "", false
+	}
+	p := token.Position{Filename: filename, Line: line, Column: col}
+
+	src, err := h.readFile(filename)
+	if err != nil {
+		return p, "", true
+	}
+	offset, ok := lineColOffset(src, line, col)
+	if !ok {
+		return p, "", true
+	}
+	p.Offset = offset
+
+	return p, fmt.Sprintf("%s:#%d", filename, offset), true
+}
+
+// lineColOffset returns the byte offset of the 1-based line and col in src.
+func lineColOffset(src []byte, line, col int) (int, bool) {
+	if line < 1 || col < 1 {
+		return 0, false
+	}
+
+	offset := 0
+	for i := 1; i < line; i++ {
+		nl := bytes.IndexByte(src[offset:], '\n')
+		if nl < 0 {
+			return 0, false
+		}
+		offset += nl + 1
+	}
+	offset += col - 1
+	if offset > len(src) {
+		return 0, false
+	}
+
+	return offset, true
+}
+
+// renderCallHierarchy renders the call hierarchy tree h, and returns the
+// buffer lines and the node of each line.
+func renderCallHierarchy(h *callHierarchy) ([]string, []*callNode) {
+	direction := "callers"
+	if h.Outgoing {
+		direction = "callees"
+	}
+	lines := []string{fmt.Sprintf("GoCallHierarchy: %s of %s", direction, h.Root.Name)}
+	nodes := []*callNode{nil}
+
+	var walk func(n *callNode, depth int)
+	walk = func(n *callNode, depth int) {
+		mark := "+"
+		switch {
+		case n.Expanded:
+			mark = "-"
+		case n.Loaded && len(n.Children) == 0, n.Query == "":
+			mark = " "
+		}
+
+		line := fmt.Sprintf("%s%s %s", strings.Repeat("  ", depth), mark, n.Name)
+		if n.Site.IsValid() {
+			line += fmt.Sprintf("  %s:%d:%d", pathutil.Rel(h.Cwd, n.Site.Filename), n.Site.Line, n.Site.Column)
+		}
+		lines = append(lines, line)
+		nodes = append(nodes, n)
+
+		if n.Expanded {
+			for _, child := range n.Children {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(h.Root, 0)
+
+	return lines, nodes
+}
+
+// refreshCallHierarchy re-renders the call hierarchy buffer if opened.
+func (c *Command) refreshCallHierarchy() error {
+	if !callHierarchyBuf.isValid(c) {
+		return nil
+	}
+
+	callHierarchyMu.Lock()
+	var lines []string
+	if lastCallHierarchy != nil {
+		lines, callHierarchyLines = renderCallHierarchy(lastCallHierarchy)
+	}
+	callHierarchyMu.Unlock()
+
+	return callHierarchyBuf.setLines(c, lines)
+}
+
+func (c *Command) cmdCallHierarchyAction(action string, line int) {
+	go func() {
+		if err := c.callHierarchyAction(action, line); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// callHierarchyAction handles the mapping action of the call hierarchy buffer.
+// The guru query of the toggle and direction actions runs without holding
+// callHierarchyMu, and its result is discarded if the direction is changed during the query.
+func (c *Command) callHierarchyAction(action string, line int) error {
+	callHierarchyMu.Lock()
+	h := lastCallHierarchy
+	var n *callNode
+	if line >= 1 && line <= len(callHierarchyLines) {
+		n = callHierarchyLines[line-1]
+	}
+	callHierarchyMu.Unlock()
+	if h == nil {
+		return nil
+	}
+
+	switch action {
+	case "jump":
+		if n == nil || !n.Site.IsValid() {
+			return nil
+		}
+		batch := c.Nvim.NewBatch()
+		batch.Command("wincmd p")
+		batch.Command(fmt.Sprintf("execute 'edit' fnameescape(%s)", strconv.Quote(n.Site.Filename)))
+		batch.Command(fmt.Sprintf("call cursor(%d, %d)", n.Site.Line, n.Site.Column))
+		batch.Command("normal! zz")
+		return errors.WithStack(batch.Execute())
+
+	case "toggle":
+		if n == nil {
+			return nil
+		}
+		callHierarchyMu.Lock()
+		load := false
+		switch {
+		case n.Expanded:
+			n.Expanded = false
+		case n.Loaded:
+			n.Expanded = true
+		default:
+			load = true
+		}
+		outgoing := h.Outgoing
+		callHierarchyMu.Unlock()
+		if load {
+			if err := c.loadCallNode(h, n, outgoing); err != nil {
+				return err
+			}
+		}
+		return c.refreshCallHierarchy()
+
+	case "direction":
+		callHierarchyMu.Lock()
+		h.Outgoing = !h.Outgoing
+		h.Root.Children, h.Root.Loaded, h.Root.Expanded = nil, false, false
+		outgoing := h.Outgoing
+		callHierarchyMu.Unlock()
+		// clear the old direction tree while querying
+		if err := c.refreshCallHierarchy(); err != nil {
+			return err
+		}
+		if err := c.loadCallNode(h, h.Root, outgoing); err != nil {
+			return err
+		}
+		return c.refreshCallHierarchy()
+	}
+
+	return nil
+}
+
+// loadCallNode queries the children of n in the outgoing direction without
+// holding callHierarchyMu, and expands n if the direction of h is not changed
+// and n is not loaded by the other action during the query.
+func (c *Command) loadCallNode(h *callHierarchy, n *callNode, outgoing bool) error {
+	nvimutil.EchoProgress(c.Nvim, "GoCallHierarchy", "analysing %s", n.Name)
+	defer nvimutil.ClearMsg(c.Nvim)
+
+	children, err := h.query(n, outgoing)
+	if err != nil {
+		return err
+	}
+
+	callHierarchyMu.Lock()
+	defer callHierarchyMu.Unlock()
+	if h.Outgoing == outgoing && !n.Loaded {
+		n.Children, n.Loaded, n.Expanded = children, true, true
+	}
+
+	return nil
+}
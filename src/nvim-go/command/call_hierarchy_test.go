@@ -0,0 +1,190 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var callHierarchyTestSrc = []byte(`package foo
+
+func Foo() {
+	bar()
+}
+
+func bar() {}
+
+var x = 1
+`)
+
+func TestCallHierarchyRoot(t *testing.T) {
+	type args struct {
+		offset int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "inside function body",
+			args: args{offset: strings.Index(string(callHierarchyTestSrc), "bar()")},
+			want: "foo.Foo",
+		},
+		{
+			name: "function name",
+			args: args{offset: strings.Index(string(callHierarchyTestSrc), "bar() {}")},
+			want: "foo.bar",
+		},
+		{
+			name:    "outside function",
+			args:    args{offset: strings.Index(string(callHierarchyTestSrc), "var x")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := callHierarchyRoot("foo.go", callHierarchyTestSrc, tt.args.offset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("callHierarchyRoot(%v) error = %v, wantErr %v", tt.args.offset, err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("callHierarchyRoot(%v) = %v, want %v", tt.args.offset, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineColOffset(t *testing.T) {
+	type args struct {
+		line int
+		col  int
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   int
+		wantOk bool
+	}{
+		{
+			name:   "first line",
+			args:   args{line: 1, col: 9},
+			want:   8,
+			wantOk: true,
+		},
+		{
+			name:   "call site",
+			args:   args{line: 4, col: 2},
+			want:   strings.Index(string(callHierarchyTestSrc), "bar()"),
+			wantOk: true,
+		},
+		{
+			name:   "out of range line",
+			args:   args{line: 100, col: 1},
+			wantOk: false,
+		},
+		{
+			name:   "invalid col",
+			args:   args{line: 1, col: 0},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := lineColOffset(callHierarchyTestSrc, tt.args.line, tt.args.col)
+			if ok != tt.wantOk {
+				t.Errorf("lineColOffset(%v, %v) ok = %v, want %v", tt.args.line, tt.args.col, ok, tt.wantOk)
+				return
+			}
+			if ok && got != tt.want {
+				t.Errorf("lineColOffset(%v, %v) = %v, want %v", tt.args.line, tt.args.col, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderCallHierarchy(t *testing.T) {
+	newTree := func() *callNode {
+		return &callNode{
+			Name:     "foo.Foo",
+			Site:     token.Position{Filename: "/src/foo/foo.go", Line: 3, Column: 6},
+			Query:    "/src/foo/foo.go:#19",
+			Loaded:   true,
+			Expanded: true,
+			Children: []*callNode{
+				{
+					Name:  "foo.bar",
+					Site:  token.Position{Filename: "/src/foo/foo.go", Line: 4, Column: 5},
+					Query: "/src/foo/foo.go:#37",
+				},
+				{
+					Name:   "foo.baz",
+					Site:   token.Position{Filename: "/src/foo/baz.go", Line: 10, Column: 2},
+					Query:  "/src/foo/baz.go:#100",
+					Loaded: true,
+				},
+				{
+					Name: "(*foo.T).wrapper",
+					Site: token.Position{Filename: "/src/foo/foo.go", Line: 5, Column: 2},
+				},
+			},
+		}
+	}
+
+	type args struct {
+		outgoing bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "callers",
+			args: args{outgoing: false},
+			want: []string{
+				"GoCallHierarchy: callers of foo.Foo",
+				"- foo.Foo  foo.go:3:6",
+				"  + foo.bar  foo.go:4:5",
+				"    foo.baz  baz.go:10:2",
+				"    (*foo.T).wrapper  foo.go:5:2",
+			},
+		},
+		{
+			name: "callees",
+			args: args{outgoing: true},
+			want: []string{
+				"GoCallHierarchy: callees of foo.Foo",
+				"- foo.Foo  foo.go:3:6",
+				"  + foo.bar  foo.go:4:5",
+				"    foo.baz  baz.go:10:2",
+				"    (*foo.T).wrapper  foo.go:5:2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := &callHierarchy{Root: newTree(), Outgoing: tt.args.outgoing, Cwd: "/src/foo"}
+			got, nodes := renderCallHierarchy(h)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderCallHierarchy(%v) = %q, want %q", tt.args.outgoing, got, tt.want)
+			}
+			if len(nodes) != len(got) || nodes[0] != nil || nodes[1] != h.Root {
+				t.Errorf("renderCallHierarchy(%v) nodes = %v, want the node of each line", tt.args.outgoing, nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ----------------------------------------------------------------------------
+// GoCallgraph
+
+// CmdCallgraphEval struct type for Eval of GoCallgraph command.
+type CmdCallgraphEval struct {
+	Cwd  string `msgpack:",array"`
+	File string
+}
+
+// callgraphOption represents the GoCallgraph arguments.
+type callgraphOption struct {
+	// Algo call graph algorithm. "static", "cha" or "pointer".
+	Algo string
+	// Focus import path of the package, or the function name such as
+	// "nvim-go/command.NewCommand", "(*Command).Guru" or "Guru".
+	Focus string
+	// Depth depth limit of the calls from the focus functions. 0 is unlimited.
+	Depth int
+}
+
+var (
+	callgraphMu sync.Mutex
+	// callgraphPositions the jump position of each line of the call graph buffer.
+	callgraphPositions []token.Position
+
+	callgraphBuf = newScratchBuffer("__GO_CALLGRAPH__", nvimutil.FiletypeGoCallgraph, "botright split")
+)
+
+func (c *Command) cmdCallgraph(args []string, eval *CmdCallgraphEval) {
+	go func() {
+		if err := c.Callgraph(args, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Callgraph builds the call graph of the guru scope, and writes the DOT file
+// of the calls from the focus functions to the g:go#guru#callgraph#dot, and
+// shows its tree view to the buffer.
+// The args are the algorithm, the focus and the depth in any order. The
+// default focus is the package of the current file.
+func (c *Command) Callgraph(args []string, eval *CmdCallgraphEval) error {
+	defer nvimutil.Profile(time.Now(), "GoCallgraph")
+
+	opt, err := parseCallgraphArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if opt.Focus == "" {
+		// same as the guruScope, such as the module package
+		pkgID, err := pathutil.PackageID(filepath.Dir(eval.File))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		opt.Focus = pkgID
+	}
+
+	scope, err := c.guruScope(eval.File)
+	if err != nil {
+		return err
+	}
+	query := guru.Query{
+		Build:      buildTagsContext(),
+		Scope:      scope,
+		Reflection: config.GuruReflection,
+	}
+	if config.GuruCache {
+		query.Cache = guruCache
+	}
+
+	nvimutil.EchoProgress(c.Nvim, "GoCallgraph", "building %s call graph", opt.Algo)
+	cg, err := guru.CallGraph(&query, opt.Algo)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	roots := focusNodes(cg, opt.Focus)
+	if len(roots) == 0 {
+		return errors.Errorf("GoCallgraph: no functions matched to %s", opt.Focus)
+	}
+
+	dot := config.GuruCallgraphDot
+	if !filepath.IsAbs(dot) {
+		dot = filepath.Join(eval.Cwd, dot)
+	}
+	if err := ioutil.WriteFile(dot, callgraphDOT(roots, opt.Depth), 0644); err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines, positions := callgraphTree(roots, opt.Depth, eval.Cwd)
+	header := fmt.Sprintf("GoCallgraph: %s call graph of %s", opt.Algo, opt.Focus)
+	if opt.Depth > 0 {
+		header += fmt.Sprintf(" (depth %d)", opt.Depth)
+	}
+	lines = append([]string{header, "DOT: " + pathutil.Rel(eval.Cwd, dot)}, lines...)
+	positions = append([]token.Position{{}, {}}, positions...)
+
+	callgraphMu.Lock()
+	callgraphPositions = positions
+	callgraphMu.Unlock()
+
+	nnoremap := map[string]string{
+		"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'GoCallgraphJump', line('.'))<CR>", config.ChannelID),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := callgraphBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+	defer nvimutil.ClearMsg(c.Nvim)
+
+	return callgraphBuf.setLines(c, lines)
+}
+
+// parseCallgraphArgs parses the GoCallgraph args. The algorithm name and the
+// number of the depth are distinguished from the focus, so the order is free.
+func parseCallgraphArgs(args []string) (*callgraphOption, error) {
+	opt := &callgraphOption{
+		Algo:  config.GuruCallgraphAlgo,
+		Depth: int(config.GuruCallgraphDepth),
+	}
+
+	for _, arg := range args {
+		switch arg {
+		case guru.CallGraphStatic, guru.CallGraphCHA, guru.CallGraphPointer:
+			opt.Algo = arg
+			continue
+		}
+		if depth, err := strconv.Atoi(arg); err == nil {
+			if depth < 0 {
+				return nil, errors.Errorf("GoCallgraph: invalid depth: %d", depth)
+			}
+			opt.Depth = depth
+			continue
+		}
+		if opt.Focus != "" {
+			return nil, errors.Errorf("GoCallgraph: too many focus: %s and %s", opt.Focus, arg)
+		}
+		opt.Focus = arg
+	}
+
+	return opt, nil
+}
+
+// cmdCallgraphComplete completes the call graph algorithms.
+func (c *Command) cmdCallgraphComplete(a *nvim.CommandCompletionArgs) ([]string, error) {
+	var candidates []string
+	for _, algo := range []string{guru.CallGraphCHA, guru.CallGraphPointer, guru.CallGraphStatic} {
+		if strings.HasPrefix(algo, a.ArgLead) {
+			candidates = append(candidates, algo)
+		}
+	}
+	return candidates, nil
+}
+
+// focusNodes returns the nodes of cg that matched to the focus, sorted by the name.
+// The focus matches to the named functions of the package, or the function
+// name with or without the package.
+func focusNodes(cg *callgraph.Graph, focus string) []*callgraph.Node {
+	var roots []*callgraph.Node
+	for fn, n := range cg.Nodes {
+		if fn == nil || fn.Pkg == nil {
+			continue
+		}
+		switch {
+		case fn.Pkg.Pkg.Path() == focus && fn.Parent() == nil:
+		case fn.String() == focus, fn.RelString(fn.Pkg.Pkg) == focus:
+		default:
+			continue
+		}
+		roots = append(roots, n)
+	}
+	sortNodes(roots)
+
+	return roots
+}
+
+// calleeNodes returns the unique callees of n, sorted by the name.
+func calleeNodes(n *callgraph.Node) []*callgraph.Node {
+	seen := make(map[*callgraph.Node]bool)
+	var callees []*callgraph.Node
+	for _, edge := range n.Out {
+		if edge.Callee.Func == nil || seen[edge.Callee] {
+			continue
+		}
+		seen[edge.Callee] = true
+		callees = append(callees, edge.Callee)
+	}
+	sortNodes(callees)
+
+	return callees
+}
+
+func sortNodes(nodes []*callgraph.Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Func.String() < nodes[j].Func.String() })
+}
+
+// callgraphTree returns the tree view lines of the calls from the roots within
+// the depth, and the declaration position of the function of each line.
+// The already expanded function is not expanded again, and is marked with "...".
+func callgraphTree(roots []*callgraph.Node, depth int, cwd string) ([]string, []token.Position) {
+	var (
+		lines     []string
+		positions []token.Position
+	)
+	expanded := make(map[*callgraph.Node]bool)
+
+	var walk func(n *callgraph.Node, level int)
+	walk = func(n *callgraph.Node, level int) {
+		fn := n.Func
+		pos := fn.Prog.Fset.Position(fn.Pos())
+
+		line := strings.Repeat("  ", level) + fn.String()
+		callees := calleeNodes(n)
+		more := expanded[n] && len(callees) > 0
+		if more {
+			line += " ..."
+		}
+		if pos.IsValid() {
+			line += fmt.Sprintf("  %s:%d:%d", pathutil.Rel(cwd, pos.Filename), pos.Line, pos.Column)
+		}
+		lines = append(lines, line)
+		positions = append(positions, pos)
+
+		if more || (depth > 0 && level >= depth) {
+			return
+		}
+		expanded[n] = true
+		for _, callee := range callees {
+			walk(callee, level+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return lines, positions
+}
+
+// callgraphDOT returns the Graphviz DOT of the calls from the roots within the
+// depth. The functions are grouped by the package such as go-callvis.
+func callgraphDOT(roots []*callgraph.Node, depth int) []byte {
+	// levels the minimum depth of the reachable nodes from the roots.
+	levels := make(map[*callgraph.Node]int)
+	queue := make([]*callgraph.Node, 0, len(roots))
+	for _, root := range roots {
+		levels[root] = 0
+		queue = append(queue, root)
+	}
+	var edges [][2]*callgraph.Node
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if depth > 0 && levels[n] >= depth {
+			continue
+		}
+		for _, callee := range calleeNodes(n) {
+			edges = append(edges, [2]*callgraph.Node{n, callee})
+			if _, ok := levels[callee]; !ok {
+				levels[callee] = levels[n] + 1
+				queue = append(queue, callee)
+			}
+		}
+	}
+
+	pkgs := make(map[*ssa.Package][]*callgraph.Node)
+	for n := range levels {
+		pkgs[n.Func.Pkg] = append(pkgs[n.Func.Pkg], n)
+	}
+	pkgList := make([]*ssa.Package, 0, len(pkgs))
+	for pkg := range pkgs {
+		pkgList = append(pkgList, pkg)
+	}
+	sort.Slice(pkgList, func(i, j int) bool { return pkgPath(pkgList[i]) < pkgPath(pkgList[j]) })
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph callgraph {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=box, style=rounded];\n")
+	for i, pkg := range pkgList {
+		nodes := pkgs[pkg]
+		sortNodes(nodes)
+		fmt.Fprintf(&buf, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&buf, "\t\tlabel=%s;\n", strconv.Quote(pkgPath(pkg)))
+		for _, n := range nodes {
+			label := n.Func.String()
+			if pkg != nil {
+				label = n.Func.RelString(pkg.Pkg)
+			}
+			fmt.Fprintf(&buf, "\t\t%s [label=%s];\n", strconv.Quote(n.Func.String()), strconv.Quote(label))
+		}
+		buf.WriteString("\t}\n")
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&buf, "\t%s -> %s;\n", strconv.Quote(e[0].Func.String()), strconv.Quote(e[1].Func.String()))
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// pkgPath returns the import path of pkg, or empty if pkg is nil such as the wrapper functions.
+func pkgPath(pkg *ssa.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return pkg.Pkg.Path()
+}
+
+// callgraphJump jumps to the function of the line of the call graph buffer.
+func (c *Command) callgraphJump(line int) error {
+	callgraphMu.Lock()
+	var pos token.Position
+	if line >= 1 && line <= len(callgraphPositions) {
+		pos = callgraphPositions[line-1]
+	}
+	callgraphMu.Unlock()
+	if !pos.IsValid() {
+		return nil
+	}
+
+	batch := c.Nvim.NewBatch()
+	batch.Command("wincmd p")
+	batch.Command(fmt.Sprintf("execute 'edit' fnameescape(%s)", strconv.Quote(pos.Filename)))
+	batch.Command(fmt.Sprintf("call cursor(%d, %d)", pos.Line, pos.Column))
+	batch.Command("normal! zz")
+
+	return errors.WithStack(batch.Execute())
+}
@@ -0,0 +1,228 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/parser"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/config"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/static"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+var callgraphTestSrc = `package foo
+
+type T struct{}
+
+func (T) Method() { leaf() }
+
+func Foo() {
+	bar()
+	T{}.Method()
+}
+
+func bar() {
+	leaf()
+	bar()
+}
+
+func leaf() {}
+`
+
+func testCallGraph(t *testing.T) *callgraph.Graph {
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	f, err := conf.ParseFile("/src/foo/foo.go", callgraphTestSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("foo", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(lprog, 0)
+	prog.Build()
+
+	cg := static.CallGraph(prog)
+	cg.DeleteSyntheticNodes()
+	return cg
+}
+
+func TestParseCallgraphArgs(t *testing.T) {
+	config.GuruCallgraphAlgo = "static"
+	config.GuruCallgraphDepth = 3
+
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *callgraphOption
+		wantErr bool
+	}{
+		{
+			name: "default",
+			args: args{args: nil},
+			want: &callgraphOption{Algo: "static", Depth: 3},
+		},
+		{
+			name: "all options",
+			args: args{args: []string{"pointer", "nvim-go/command", "1"}},
+			want: &callgraphOption{Algo: "pointer", Focus: "nvim-go/command", Depth: 1},
+		},
+		{
+			name: "any order",
+			args: args{args: []string{"0", "(*Command).Guru", "cha"}},
+			want: &callgraphOption{Algo: "cha", Focus: "(*Command).Guru", Depth: 0},
+		},
+		{
+			name:    "negative depth",
+			args:    args{args: []string{"-1"}},
+			wantErr: true,
+		},
+		{
+			name:    "too many focus",
+			args:    args{args: []string{"foo", "bar"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCallgraphArgs(tt.args.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCallgraphArgs(%v) error = %v, wantErr %v", tt.args.args, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCallgraphArgs(%v) = %v, want %v", tt.args.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusNodes(t *testing.T) {
+	cg := testCallGraph(t)
+
+	type args struct {
+		focus string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "package",
+			args: args{focus: "foo"},
+			want: []string{"(foo.T).Method", "foo.Foo", "foo.bar", "foo.init", "foo.leaf"},
+		},
+		{
+			name: "function with package",
+			args: args{focus: "foo.bar"},
+			want: []string{"foo.bar"},
+		},
+		{
+			name: "method without package",
+			args: args{focus: "(T).Method"},
+			want: []string{"(foo.T).Method"},
+		},
+		{
+			name: "not matched",
+			args: args{focus: "baz"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, n := range focusNodes(cg, tt.args.focus) {
+				got = append(got, n.Func.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("focusNodes(%v) = %v, want %v", tt.args.focus, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallgraphTree(t *testing.T) {
+	cg := testCallGraph(t)
+
+	type args struct {
+		focus string
+		depth int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "unlimited",
+			args: args{focus: "foo.Foo", depth: 0},
+			want: []string{
+				"foo.Foo  foo.go:7:6",
+				"  (foo.T).Method  foo.go:5:10",
+				"    foo.leaf  foo.go:17:6",
+				"  foo.bar  foo.go:12:6",
+				"    foo.bar ...  foo.go:12:6",
+				"    foo.leaf  foo.go:17:6",
+			},
+		},
+		{
+			name: "depth",
+			args: args{focus: "foo.Foo", depth: 1},
+			want: []string{
+				"foo.Foo  foo.go:7:6",
+				"  (foo.T).Method  foo.go:5:10",
+				"  foo.bar  foo.go:12:6",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, positions := callgraphTree(focusNodes(cg, tt.args.focus), tt.args.depth, "/src/foo")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("callgraphTree(%v, %v) = %q, want %q", tt.args.focus, tt.args.depth, got, tt.want)
+			}
+			if len(positions) != len(got) {
+				t.Errorf("callgraphTree(%v, %v) positions = %v, want the position of each line", tt.args.focus, tt.args.depth, positions)
+			}
+		})
+	}
+}
+
+func TestCallgraphDOT(t *testing.T) {
+	cg := testCallGraph(t)
+
+	got := string(callgraphDOT(focusNodes(cg, "foo.Foo"), 1))
+	for _, want := range []string{
+		"digraph callgraph {",
+		"\tsubgraph cluster_0 {\n\t\tlabel=\"foo\";",
+		"\t\t\"foo.Foo\" [label=\"Foo\"];",
+		"\t\"foo.Foo\" -> \"foo.bar\";",
+		"\t\"foo.Foo\" -> \"(foo.T).Method\";",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("callgraphDOT(foo.Foo, 1) = %s, want contains %q", got, want)
+		}
+	}
+	// the calls from bar are deeper than the depth
+	if strings.Contains(got, "\"foo.bar\" -> ") {
+		t.Errorf("callgraphDOT(foo.Foo, 1) = %s, want no calls from foo.bar", got)
+	}
+}
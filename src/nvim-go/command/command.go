@@ -35,11 +35,29 @@ func Register(p *plugin.Plugin, ctx *ctx.Context) *Command {
 
 	// Register command and function
 	// CommandOptions order: Name, NArgs, Range, Count, Addr, Bang, Register, Eval, Bar, Complete
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBench", NArgs: "*", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdBench)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gobuild", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdBuild)
-	p.HandleCommand(&plugin.CommandOptions{Name: "GoCover", Eval: "[getcwd(), expand('%:p')]"}, c.cmdCover)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuildMatrix", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoTargetCompletion"}, c.cmdBuildMatrix)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoBuildTags", NArgs: "*", Bang: true}, c.cmdBuildTags)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCallHierarchy", NArgs: "?", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2), bufnr('%')]", Complete: "customlist,GoCallHierarchyCompletion"}, c.cmdCallHierarchy)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCallgraph", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoCallgraphCompletion"}, c.cmdCallgraph)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCover", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "file"}, c.cmdCover)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverClear"}, c.cmdCoverClear)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverHTML", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p')]", Complete: "file"}, c.cmdCoverHTML)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverSave", NArgs: "1", Eval: "getcwd()", Complete: "file"}, c.cmdCoverSave)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverReport", Bang: true, Eval: "[getcwd(), expand('%:p')]"}, c.cmdCoverReport)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoCoverToggle", Eval: "[getcwd(), expand('%:p')]"}, c.cmdCoverToggle)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDecls", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p'), &modified, bufnr('%')]"}, c.cmdDecls)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDeclsDir", NArgs: "?", Bang: true, Eval: "[getcwd(), expand('%:p'), &modified, bufnr('%')]"}, c.cmdDeclsDir)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDefPop", NArgs: "?", Eval: "win_getid()"}, c.cmdDefPop)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDefStack", Eval: "win_getid()"}, c.cmdDefStack)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoDefStackClear", Eval: "win_getid()"}, c.cmdDefStackClear)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gofmt", Eval: "expand('%:p:h')"}, c.cmdFmt)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoGenerateTest", NArgs: "*", Range: "%", Addr: "line", Bang: true, Eval: "expand('%:p:h')", Complete: "file"}, c.cmdGenerateTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoGuruScope", NArgs: "*", Bang: true, Eval: "[getcwd(), expand('%:p:h')]", Complete: "customlist,GoGuruScopeCompletion"}, c.cmdGuruScope)
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoGuru", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2)]"}, c.funcGuru)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoInfo", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2), bufnr('%')]"}, c.cmdInfo)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoInstall", NArgs: "*", Bang: true, Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoInstallCompletion"}, c.cmdInstall)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoIferr", Eval: "expand('%:p')"}, c.cmdIferr)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Golint", NArgs: "?", Eval: "expand('%:p')", Complete: "customlist,GoLintCompletion"}, c.cmdLint)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gometalinter", Eval: "getcwd()"}, c.cmdMetalinter)
@@ -47,12 +65,33 @@ func Register(p *plugin.Plugin, ctx *ctx.Context) *Command {
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gorun", NArgs: "*", Eval: "expand('%:p')"}, c.cmdRun)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GorunLast", Eval: "expand('%:p')"}, c.cmdRunLast)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Gotest", NArgs: "*", Eval: "expand('%:p:h')"}, c.cmdTest)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestFunc", NArgs: "*", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdTestFunc)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTestResults"}, c.cmdTestResults)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoTarget", NArgs: "*", Bang: true, Complete: "customlist,GoTargetCompletion"}, c.cmdTarget)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoSameIds", Eval: "[getcwd(), expand('%:p'), &modified, line2byte(line('.')) + (col('.')-2), bufnr('%')]"}, c.cmdSameIds)
+	p.HandleCommand(&plugin.CommandOptions{Name: "GoSameIdsClear"}, c.cmdSameIdsClear)
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoSwitchTest", Eval: "[getcwd(), expand('%:p'), line2byte(line('.')) + (col('.')-2)]"}, c.cmdSwitchTest)
 	p.HandleCommand(&plugin.CommandOptions{Name: "Govet", NArgs: "*", Eval: "[getcwd(), expand('%:p')]", Complete: "customlist,GoVetCompletion"}, c.cmdVet)
 
 	// Commnad completion
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoLintCompletion", Eval: "getcwd()"}, c.cmdLintComplete) // list the file, directory and go packages
 	p.HandleFunction(&plugin.FunctionOptions{Name: "GoVetCompletion", Eval: "getcwd()"}, c.cmdVetComplete)   // flag for go tool vet
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoGuruScopeCompletion", Eval: "expand('%:p:h')"}, c.cmdGuruScopeComplete)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoTargetCompletion", Eval: "getcwd()"}, c.cmdTargetComplete)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoInstallCompletion", Eval: "expand('%:p:h')"}, c.cmdInstallComplete)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoCallHierarchyCompletion"}, c.cmdCallHierarchyComplete)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "GoCallgraphCompletion"}, c.cmdCallgraphComplete)
+
+	// RPC export
+	p.Handle("GoTestResultsAction", c.testResultsAction)        // mapping actions of the test results buffer
+	p.Handle("GoCoverInvalidate", c.coverInvalidate)            // invalidates the coverage highlights of the edited buffer
+	p.Handle("GoCoverReportAction", c.coverReportAction)        // mapping actions of the coverage report buffer
+	p.Handle("GoDefStackJump", c.defStackJump)                  // <CR> mapping of the definition stack buffer
+	p.Handle("GoSameIdsClear", c.SameIdsClear)                  // clears the same identifiers highlights on CursorMoved
+	p.Handle("GoInfoClose", c.InfoClose)                        // closes the info floating window on CursorMoved
+	p.Handle("GoCallHierarchyAction", c.cmdCallHierarchyAction) // mapping actions of the call hierarchy buffer
+	p.Handle("GoCallgraphJump", c.callgraphJump)                // <CR> mapping of the call graph buffer
+	p.Handle("GoDeclsAction", c.declsAction)                    // mapping actions of the declarations picker buffer
 
 	// for debug
 	p.HandleCommand(&plugin.CommandOptions{Name: "GoByteOffset", Range: "%", Eval: "expand('%:p')"}, c.cmdByteOffset)
@@ -9,10 +9,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
-	"strings"
+	"sort"
+	"sync"
 	"time"
 
 	"nvim-go/config"
@@ -30,9 +33,9 @@ type cmdCoverEval struct {
 	File string `msgpack:",array"`
 }
 
-func (c *Command) cmdCover(eval *cmdCoverEval) {
+func (c *Command) cmdCover(args []string, eval *cmdCoverEval) {
 	go func() {
-		err := c.cover(eval)
+		err := c.cover(args, eval)
 
 		switch e := err.(type) {
 		case error:
@@ -52,81 +55,456 @@ func (c *Command) cmdCover(eval *cmdCoverEval) {
 
 // cover run the go tool cover command and highlight current buffer based cover
 // profile result.
-func (c *Command) cover(eval *cmdCoverEval) interface{} {
+// If args is "diff [ref]", runs the diff coverage mode that reports the
+// uncovered lines changed since ref. Otherwise args are the extra profile
+// files, such as the integration tests profile, that merged with the result.
+func (c *Command) cover(args []string, eval *cmdCoverEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoCover")
 
+	if len(args) > 0 && args[0] == "diff" {
+		ref := config.CoverDiffBase
+		if len(args) > 1 {
+			ref = args[1]
+		}
+		return c.coverDiff(ref, eval)
+	}
+
+	// read the extra profiles first, and run the go test with their mode
+	// because the merged profiles must have the same mode
+	mode := config.CoverMode
+	var extras [][]*cover.Profile
+	for _, arg := range args {
+		if !filepath.IsAbs(arg) {
+			arg = filepath.Join(eval.Cwd, arg)
+		}
+		prof, err := cover.ParseProfiles(arg)
+		if err != nil {
+			return errors.Wrapf(err, "parse %s", arg)
+		}
+		extras = append(extras, prof)
+	}
+	for _, prof := range extras {
+		if len(prof) > 0 {
+			mode = prof[0].Mode
+			break
+		}
+	}
+
+	dir := filepath.Dir(eval.File)
+	profile, errlist, err := c.coverProfile(dir, []string{"."}, mode)
+	switch {
+	case err != nil:
+		return err
+	case errlist != nil:
+		return errlist
+	}
+
+	pkgDir := func(string) (string, bool) { return dir, true }
+	if len(extras) > 0 {
+		profiles := append([][]*cover.Profile{profile}, extras...)
+		if profile, err = cover.MergeProfiles(profiles...); err != nil {
+			return errors.WithStack(err)
+		}
+
+		// the extra profiles may contain the other packages
+		pkgDir = func(importPath string) (string, bool) {
+			if len(profiles[0]) > 0 && importPath == path.Dir(profiles[0][0].FileName) {
+				return dir, true
+			}
+			return c.testPackageDir(importPath)
+		}
+	}
+	files := coverFiles(profile, pkgDir)
+
+	return c.setCoverage(files, eval.File)
+}
+
+// coverProfile runs the go test with coverage profiling for pkgs in dir with
+// the mode covermode, and parses the profile result.
+// Returns the errorlist if the test is failed.
+func (c *Command) coverProfile(dir string, pkgs []string, mode string) ([]*cover.Profile, []*nvim.QuickfixError, error) {
 	coverFile, err := ioutil.TempFile(os.TempDir(), "nvim-go-cover")
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
 	defer os.Remove(coverFile.Name())
 
-	cmd := exec.Command("go", strings.Fields(fmt.Sprintf("test -cover -covermode=%s -coverprofile=%s .", config.CoverMode, coverFile.Name()))...)
+	cmd := exec.Command("go", "test", "-cover", "-covermode="+mode, "-coverprofile="+coverFile.Name())
+	cmd.Args = append(cmd.Args, pkgs...)
+	cmd.Args = append(cmd.Args, buildTagsFlag()...)
 	if len(config.CoverFlags) > 0 {
 		cmd.Args = append(cmd.Args, config.CoverFlags...)
 	}
-	cmd.Dir = filepath.Dir(eval.File)
+	cmd.Dir = dir
 
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
 	if coverErr := cmd.Run(); coverErr != nil && coverErr.(*exec.ExitError) != nil {
-		errlist, err := nvimutil.ParseError(stdout.Bytes(), filepath.Dir(eval.File), &c.ctx.Build, nil)
+		errlist, err := nvimutil.ParseError(stdout.Bytes(), dir, &c.ctx.Build, nil)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
-		return errlist
+		return nil, errlist, nil
 	}
 	delete(c.ctx.Errlist, "Cover")
 
 	profile, err := cover.ParseProfiles(coverFile.Name())
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
 
+	return profile, nil, nil
+}
+
+// setCoverage replaces the last coverage result to files, and highlights the current buffer of file.
+func (c *Command) setCoverage(files map[string]*cover.Profile, file string) error {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+
+	// clear the previous result highlights
+	c.clearCoverAll()
+	coverage.files = files
+	coverage.visible = true
+	coverage.invalid = make(map[nvim.Buffer]bool)
+
 	b, err := c.Nvim.CurrentBuffer()
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+
+	return c.applyCover(b, file)
+}
+
+// coverage represents the last coverage profile result and the highlighted buffers.
+var coverage = struct {
+	mu sync.Mutex
+
+	// files profile of the each file full path.
+	files map[string]*cover.Profile
+	// visible whether the highlights are visible. GoCoverToggle toggles it.
+	visible bool
+	// highlighted highlight namespace of the highlighted buffers.
+	highlighted map[nvim.Buffer]int
+	// invalid the edited buffers after the profiling.
+	invalid map[nvim.Buffer]bool
+}{
+	highlighted: make(map[nvim.Buffer]int),
+	invalid:     make(map[nvim.Buffer]bool),
+}
+
+// coverAugroup augroup name of the coverage invalidation autocmds.
+const coverAugroup = "nvim-go-cover"
+
+// coverFiles maps the profiles to the file full path. pkgDir returns the
+// directory of the profile import path.
+func coverFiles(profiles []*cover.Profile, pkgDir func(importPath string) (string, bool)) map[string]*cover.Profile {
+	files := make(map[string]*cover.Profile)
+	for _, prof := range profiles {
+		dir, ok := pkgDir(path.Dir(prof.FileName))
+		if !ok {
+			continue
+		}
+		files[filepath.Join(dir, path.Base(prof.FileName))] = prof
+	}
+
+	return files
+}
+
+// coverLines returns the highlight group of each line of the profile blocks.
+// The line number is started by 0 for nvim_buf_add_highlight.
+func coverLines(prof *cover.Profile) map[int]string {
+	lines := make(map[int]string)
+	for _, block := range prof.Blocks {
+		for line := block.StartLine - 1; line <= block.EndLine-1; line++ {
+			// not highlighting the last RBRACE of the function
+			if line == block.EndLine-1 && block.EndCol == 2 {
+				break
+			}
+
+			var hl string
+			switch {
+			case block.Count == 0:
+				hl = "GoCoverMiss"
+			case block.Count-block.NumStmt == 0:
+				hl = "GoCoverPartial"
+			default:
+				hl = "GoCoverHit"
+			}
+			if _, ok := lines[line]; !ok {
+				lines[line] = hl
+			}
+		}
+	}
+
+	return lines
+}
+
+// coverCountSymbol prefix of the execution count virtual text.
+const coverCountSymbol = "\u00d7" // ×
+
+// coverHeatLines returns the heat-map highlight group of each line from the
+// execution count of the profile blocks, and the execution count of the
+// block start lines for the virtual text.
+// The heat level is normalized by log scale to GoCoverHeat1..GoCoverHeat10
+// same as "go tool cover -html". The line number is started by 0.
+func coverHeatLines(prof *cover.Profile) (map[int]string, map[int]int) {
+	max := 0
+	for _, block := range prof.Blocks {
+		if block.Count > max {
+			max = block.Count
+		}
+	}
+	divisor := math.Log(float64(max))
+
+	lines := make(map[int]string)
+	counts := make(map[int]int)
+	for _, block := range prof.Blocks {
+		hl := "GoCoverMiss"
+		if block.Count > 0 {
+			level := 10
+			if max > 1 {
+				level = int(math.Floor(math.Log(float64(block.Count))/divisor*9)) + 1
+			}
+			hl = fmt.Sprintf("GoCoverHeat%d", level)
+		}
+
+		if _, ok := counts[block.StartLine-1]; !ok {
+			counts[block.StartLine-1] = block.Count
+		}
+		for line := block.StartLine - 1; line <= block.EndLine-1; line++ {
+			// not highlighting the last RBRACE of the function
+			if line == block.EndLine-1 && block.EndCol == 2 {
+				break
+			}
+			if _, ok := lines[line]; !ok {
+				lines[line] = hl
+			}
+		}
+	}
+
+	return lines, counts
+}
+
+// isCoverCount reports whether shows the execution count of the prof.
+func isCoverCount(prof *cover.Profile) bool {
+	return config.CoverCount && (prof.Mode == "count" || prof.Mode == "atomic")
+}
+
+// applyCover highlights the b buffer of file based cover profile result.
+// It does nothing if the file is not profiled, or the buffer is edited after the profiling.
+// coverage.mu must be held.
+func (c *Command) applyCover(b nvim.Buffer, file string) error {
+	if !coverage.visible || coverage.invalid[b] {
+		return nil
+	}
+
+	prof, ok := coverage.files[file]
+	if !ok {
+		return nil
+	}
+
+	if config.DebugEnable {
+		buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		log.Printf("prof.Blocks:\n%+v\n", spew.Sdump(prof.Blocks))
+		log.Printf("prof.Boundaries():\n%+v\n", spew.Sdump(prof.Boundaries(nvimutil.ToByteSlice(buf))))
+	}
+
+	ns, err := nvimutil.CreateNamespace(c.Nvim, fmt.Sprintf("%s-%d", coverAugroup, b))
 	if err != nil {
-		return errors.WithStack(err)
+		return err
+	}
+
+	lines, counts := coverLines(prof), map[int]int(nil)
+	if isCoverCount(prof) {
+		lines, counts = coverHeatLines(prof)
 	}
 
-	highlighted := make(map[int]bool)
 	var res int // for ignore the msgpack decode errror. not used
 	batch := c.Nvim.NewBatch()
-	for _, prof := range profile {
-		if filepath.Base(prof.FileName) == filepath.Base(eval.File) {
+	batch.Call("nvim_buf_clear_namespace", nil, int(b), ns, 0, -1)
+	for line, hl := range lines {
+		batch.AddBufferHighlight(b, ns, hl, line, 0, -1, &res)
+	}
+	for line, count := range counts {
+		chunks := [][]string{{fmt.Sprintf("%s%d", coverCountSymbol, count), "GoCoverCount"}}
+		batch.Call("nvim_buf_set_virtual_text", &res, int(b), ns, line, chunks, map[string]interface{}{})
+	}
 
-			if config.DebugEnable {
-				log.Printf("prof.Blocks:\n%+v\n", spew.Sdump(prof.Blocks))
-				log.Printf("prof.Boundaries():\n%+v\n", spew.Sdump(prof.Boundaries(nvimutil.ToByteSlice(buf))))
-			}
-			for _, block := range prof.Blocks {
-				for line := block.StartLine - 1; line <= block.EndLine-1; line++ { // nvim_buf_add_highlight line started by 0
-					// not highlighting the last RBRACE of the function
-					if line == block.EndLine-1 && block.EndCol == 2 {
-						break
-					}
-
-					var hl string
-					switch {
-					case block.Count == 0:
-						hl = "GoCoverMiss"
-					case block.Count-block.NumStmt == 0:
-						hl = "GoCoverPartial"
-					default:
-						hl = "GoCoverHit"
-					}
-					if !highlighted[line] {
-						batch.AddBufferHighlight(b, 0, hl, line, 0, -1, &res)
-						highlighted[line] = true
-					}
-				}
-			}
+	// invalidates the highlights when the buffer is edited
+	batch.Command("augroup " + coverAugroup)
+	batch.Command("augroup END")
+	batch.Command(fmt.Sprintf("autocmd! %s * <buffer=%d>", coverAugroup, b))
+	batch.Command(fmt.Sprintf("autocmd %s TextChanged,TextChangedI <buffer=%d> call rpcnotify(%d, 'GoCoverInvalidate', %d)", coverAugroup, b, config.ChannelID, b))
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+	coverage.highlighted[b] = ns
+
+	return nil
+}
+
+// clearCover clears the coverage highlights and the invalidation autocmd of the b buffer.
+// coverage.mu must be held.
+func (c *Command) clearCover(b nvim.Buffer) {
+	ns, ok := coverage.highlighted[b]
+	if !ok {
+		return
+	}
+	delete(coverage.highlighted, b)
+
+	if nvimutil.IsBufferValid(c.Nvim, b) {
+		nvimutil.ClearNamespace(c.Nvim, b, ns)
+		c.Nvim.Command(fmt.Sprintf("autocmd! %s * <buffer=%d>", coverAugroup, b))
+	}
+}
+
+// clearCoverAll clears the coverage highlights of all buffers.
+// coverage.mu must be held.
+func (c *Command) clearCoverAll() {
+	for b := range coverage.highlighted {
+		c.clearCover(b)
+	}
+}
+
+// CoverBufEnter reapplies the coverage highlights when entering the other
+// file buffer of the profiled package.
+func (c *Command) CoverBufEnter(bufnr int, file string) error {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+
+	b := nvim.Buffer(bufnr)
+	if _, ok := coverage.highlighted[b]; ok {
+		return nil
+	}
+	return c.applyCover(b, file)
+}
+
+// coverInvalidate clears the coverage highlights of the edited buffer, the
+// highlights are not reapplied until the next GoCover.
+func (c *Command) coverInvalidate(bufnr int) {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+
+	b := nvim.Buffer(bufnr)
+	c.clearCover(b)
+	coverage.invalid[b] = true
+}
+
+// ----------------------------------------------------------------------------
+// GoCoverSave
+
+func (c *Command) cmdCoverSave(args []string, cwd string) {
+	go func() {
+		if err := c.CoverSave(args, cwd); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// CoverSave saves the last coverage profile, that merged the extra profiles, to the args[0] file
+// for use it as the extra profile of GoCover in later sessions.
+func (c *Command) CoverSave(args []string, cwd string) error {
+	defer nvimutil.Profile(time.Now(), "GoCoverSave")
+
+	coverage.mu.Lock()
+	profiles := make([]*cover.Profile, 0, len(coverage.files))
+	for _, prof := range coverage.files {
+		profiles = append(profiles, prof)
+	}
+	coverage.mu.Unlock()
+	if len(profiles) == 0 {
+		return nvimutil.Echoerr(c.Nvim, "GoCoverSave: no coverage profile. Run GoCover first")
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].FileName < profiles[j].FileName })
+
+	out := args[0]
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(cwd, out)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := cover.WriteProfiles(f, profiles); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nvimutil.Echomsg(c.Nvim, "GoCoverSave: wrote", out)
+}
+
+// ----------------------------------------------------------------------------
+// GoCoverClear
+
+func (c *Command) cmdCoverClear() {
+	go c.CoverClear()
+}
+
+// CoverClear clears the all coverage highlights and forgets the profile result.
+func (c *Command) CoverClear() error {
+	defer nvimutil.Profile(time.Now(), "GoCoverClear")
+
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+
+	c.clearCoverAll()
+	coverage.files = nil
+	coverage.visible = false
+	c.errs.Delete("Cover")
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// GoCoverToggle
+
+func (c *Command) cmdCoverToggle(eval *cmdCoverEval) {
+	go func() {
+		err := c.CoverToggle(eval)
+
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Cover", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// CoverToggle toggles the coverage highlights visibility.
+// Runs the GoCover if there is no profile result.
+func (c *Command) CoverToggle(eval *cmdCoverEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoCoverToggle")
+
+	coverage.mu.Lock()
+	switch {
+	case coverage.visible:
+		c.clearCoverAll()
+		coverage.visible = false
+		coverage.mu.Unlock()
+		return nil
+	case coverage.files != nil:
+		coverage.visible = true
+		coverage.mu.Unlock()
+
+		b, err := c.Nvim.CurrentBuffer()
+		if err != nil {
+			return errors.WithStack(err)
 		}
+		return c.CoverBufEnter(int(b), eval.File)
 	}
+	coverage.mu.Unlock()
 
-	return errors.WithStack(batch.Execute())
+	return c.cover(nil, eval)
 }
@@ -0,0 +1,342 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nvim-go/config"
+	"nvim-go/internal/cover"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// coverDiffPackage represents a package directory that has the changed files.
+type coverDiffPackage struct {
+	// Dir full path of the package directory.
+	Dir string
+	// Rel slash separated path of Dir relative from the module root.
+	Rel string
+}
+
+// coverDiff runs the coverage profiling of the packages that have the changed
+// files since the ref git revision, and reports the uncovered changed lines
+// to the errorlist with the summary percentage of the changed lines.
+// The go test runs once per module root if the repository has the nested modules.
+func (c *Command) coverDiff(ref string, eval *cmdCoverEval) interface{} {
+	root := pathutil.FindVCSRoot(filepath.Dir(eval.File))
+
+	changed, err := gitChangedLines(root, ref)
+	if err != nil {
+		return err
+	}
+
+	modules := coverDiffPackages(root, changed)
+	if len(modules) == 0 {
+		c.errs.Delete("Cover")
+		return nvimutil.Echomsg(c.Nvim, "GoCover: no changed Go files since", ref)
+	}
+	modRoots := make([]string, 0, len(modules))
+	for modRoot := range modules {
+		modRoots = append(modRoots, modRoot)
+	}
+	sort.Strings(modRoots)
+
+	var (
+		profiles []*cover.Profile
+		errlist  []*nvim.QuickfixError
+	)
+	for _, modRoot := range modRoots {
+		var pkgs []string
+		for _, pkg := range modules[modRoot] {
+			if pkg.Rel == "." {
+				pkgs = append(pkgs, ".")
+				continue
+			}
+			pkgs = append(pkgs, "./"+pkg.Rel)
+		}
+		profs, errs, err := c.coverProfile(modRoot, pkgs, config.CoverMode)
+		if err != nil {
+			return err
+		}
+		errlist = append(errlist, errs...)
+		profiles = append(profiles, profs...)
+	}
+	if errlist != nil {
+		return errlist
+	}
+
+	// resolve the package directory from the changed directories if the
+	// import path is not found such as the gb project
+	modPaths := make(map[string]string)
+	for _, modRoot := range modRoots {
+		if modPath, err := pathutil.ModulePath(modRoot); err == nil {
+			modPaths[modRoot] = modPath
+		}
+	}
+	pkgDir := func(importPath string) (string, bool) {
+		if dir, ok := c.testPackageDir(importPath); ok {
+			return dir, true
+		}
+		for modRoot, pkgs := range modules {
+			for _, pkg := range pkgs {
+				if modPath, ok := modPaths[modRoot]; ok && importPath == path.Join(modPath, pkg.Rel) {
+					return pkg.Dir, true
+				}
+				if pkg.Rel != "." && (importPath == pkg.Rel || strings.HasSuffix(importPath, "/"+pkg.Rel)) {
+					return pkg.Dir, true
+				}
+			}
+		}
+		return "", false
+	}
+	files := coverFiles(profiles, pkgDir)
+
+	abs := make(map[string][]int)
+	for file, lines := range changed {
+		abs[filepath.Join(root, file)] = lines
+	}
+	covered, total, uncovered := diffCoverage(abs, files)
+
+	if err := c.setCoverage(files, eval.File); err != nil {
+		return err
+	}
+	if err := nvimutil.Echomsg(c.Nvim, fmt.Sprintf("GoCover: %.1f%% of changed lines covered (%d/%d) since %s", coverPercent(covered, total), covered, total, ref)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(uncovered) == 0 {
+		c.errs.Delete("Cover")
+		return nil
+	}
+	return uncovered
+}
+
+// coverDiffPackages groups the package directories of the changed non-test Go
+// files by the module root. The file paths of changed are relative from root,
+// and the packages outside of any module belong to root such as the GOPATH project.
+// The testdata, vendor and the "_" or "." prefixed directories are skipped
+// the same as pathutil.FindAllPackage.
+func coverDiffPackages(root string, changed map[string][]int) map[string][]*coverDiffPackage {
+	modules := make(map[string][]*coverDiffPackage)
+	seen := make(map[string]bool)
+	for file := range changed {
+		if filepath.Ext(file) != ".go" || strings.HasSuffix(file, testSuffix) {
+			continue
+		}
+		dir := filepath.Join(root, filepath.Dir(file))
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if skipCoverDir(filepath.Dir(file)) {
+			continue
+		}
+
+		modRoot, err := pathutil.FindModuleRoot(dir)
+		if err != nil || !strings.HasPrefix(dir+string(filepath.Separator), modRoot+string(filepath.Separator)) {
+			modRoot = root
+		}
+		rel, err := filepath.Rel(modRoot, dir)
+		if err != nil {
+			continue
+		}
+		modules[modRoot] = append(modules[modRoot], &coverDiffPackage{Dir: dir, Rel: filepath.ToSlash(rel)})
+	}
+
+	for _, pkgs := range modules {
+		sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Rel < pkgs[j].Rel })
+	}
+
+	return modules
+}
+
+// skipCoverDir reports whether the relative dir is or is under the testdata,
+// vendor or the "_" or "." prefixed directory.
+func skipCoverDir(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(dir), "/") {
+		if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || elem == "vendor" {
+			return true
+		}
+	}
+	return false
+}
+
+// gitChangedLines returns the added or modified line numbers of each file
+// since the ref revision, including the working tree changes and the untracked files.
+// The file path is relative from the root repository directory.
+func gitChangedLines(root, ref string) (map[string][]int, error) {
+	// core.quotePath=false keeps the non-ASCII file names unquoted, but the
+	// names that have the special characters are still quoted
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "-U0", ref, "--")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git diff %s", ref)
+	}
+
+	changed, err := parseDiffLines(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd = exec.Command("git", "-c", "core.quotePath=false", "ls-files", "--others", "--exclude-standard")
+	cmd.Dir = root
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "git ls-files")
+	}
+	for _, file := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if file == "" {
+			continue
+		}
+		file = unquoteGitPath(file)
+		buf, err := ioutil.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		n := bytes.Count(buf, []byte{'\n'})
+		if len(buf) > 0 && buf[len(buf)-1] != '\n' {
+			n++
+		}
+		for line := 1; line <= n; line++ {
+			changed[file] = append(changed[file], line)
+		}
+	}
+
+	return changed, nil
+}
+
+// diffHunkRe regexp of the unified diff hunk header such as "@@ -1,2 +3,4 @@".
+var diffHunkRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiffLines parses the unified diff, and returns the added line numbers of each file.
+func parseDiffLines(r io.Reader) (map[string][]int, error) {
+	changed := make(map[string][]int)
+
+	var file string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = unquoteGitPath(strings.TrimPrefix(line, "+++ "))
+			if file == "/dev/null" {
+				file = ""
+				continue
+			}
+			file = strings.TrimPrefix(file, "b/")
+		case file != "" && strings.HasPrefix(line, "@@ "):
+			m := diffHunkRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			for i := 0; i < count; i++ {
+				changed[file] = append(changed[file], start+i)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return changed, nil
+}
+
+// unquoteGitPath unquotes the git quoted file name such as "b/foo\tbar.go".
+func unquoteGitPath(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		if u, err := strconv.Unquote(name); err == nil {
+			return u
+		}
+	}
+	return name
+}
+
+// diffCoverage intersects the changed lines with the profile blocks of files,
+// and returns the number of covered and total statement lines, and the
+// errorlist of the uncovered changed lines grouped by the consecutive lines.
+func diffCoverage(changed map[string][]int, files map[string]*cover.Profile) (covered, total int, errlist []*nvim.QuickfixError) {
+	names := make([]string, 0, len(changed))
+	for file := range changed {
+		if _, ok := files[file]; ok {
+			names = append(names, file)
+		}
+	}
+	sort.Strings(names)
+
+	for _, file := range names {
+		hl := coverLines(files[file])
+
+		lines := append([]int(nil), changed[file]...)
+		sort.Ints(lines)
+
+		// prev is the previous changed line for group the consecutive lines
+		// over the non-statement lines such as the blank line
+		var start, end, prev int
+		flush := func() {
+			if start == 0 {
+				return
+			}
+			text := fmt.Sprintf("uncovered changed line %d", start)
+			if end > start {
+				text = fmt.Sprintf("uncovered changed lines %d-%d", start, end)
+			}
+			errlist = append(errlist, &nvim.QuickfixError{FileName: file, LNum: start, Col: 1, Text: text})
+			start, end = 0, 0
+		}
+
+		for _, line := range lines {
+			consecutive := line == prev+1
+			prev = line
+
+			group, ok := hl[line-1]
+			if !ok {
+				if !consecutive {
+					flush()
+				}
+				continue // not a statement line
+			}
+			total++
+			if group != "GoCoverMiss" {
+				covered++
+				flush()
+				continue
+			}
+			if start != 0 && consecutive {
+				end = line
+				continue
+			}
+			flush()
+			start, end = line, line
+		}
+		flush()
+	}
+
+	return covered, total, errlist
+}
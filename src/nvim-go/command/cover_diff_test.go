@@ -0,0 +1,174 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nvim-go/internal/cover"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+const testDiffOutput = `diff --git a/foo/foo.go b/foo/foo.go
+index 1111111..2222222 100644
+--- a/foo/foo.go
++++ b/foo/foo.go
+@@ -3,0 +4,3 @@ func Foo() {
++	a()
++	b()
++	c()
+@@ -10 +13 @@ func Bar() {
+-	d()
++	e()
+diff --git a/bar.go b/bar.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/bar.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package foo
+-
+diff --git a/baz.go b/baz.go
+index 4444444..5555555 100644
+--- a/baz.go
++++ b/baz.go
+@@ -5,2 +4,0 @@ func Baz() {
+-	f()
+-	g()
+diff --git "a/qux/with\ttab.go" "b/qux/with\ttab.go"
+index 6666666..7777777 100644
+--- "a/qux/with\ttab.go"
++++ "b/qux/with\ttab.go"
+@@ -2,0 +3 @@ func Qux() {
++	h()
+`
+
+func TestParseDiffLines(t *testing.T) {
+	got, err := parseDiffLines(strings.NewReader(testDiffOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]int{
+		"foo/foo.go":       {4, 5, 6, 13},
+		"qux/with\ttab.go": {3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDiffLines() = %v, want %v", got, want)
+	}
+}
+
+func TestCoverDiffPackages(t *testing.T) {
+	root, err := ioutil.TempDir("", "nvim-go-coverdiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := os.MkdirAll(filepath.Join(root, "sub", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module foo.org/root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "sub", "go.mod"), []byte("module foo.org/sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := map[string][]int{
+		"root.go":                 {1},
+		"foo/foo.go":              {1},
+		"foo/foo_test.go":         {1},
+		"foo/README.md":           {1},
+		"sub/sub.go":              {1},
+		"sub/pkg/pkg.go":          {1},
+		"testdata/data.go":        {1},
+		"vendor/foo.org/v/v.go":   {1},
+		"_example/example.go":     {1},
+		"foo/.hidden/hidden.go":   {1},
+		"foo/with space/space.go": {1},
+	}
+	got := make(map[string][]string)
+	for modRoot, pkgs := range coverDiffPackages(root, changed) {
+		rel, _ := filepath.Rel(root, modRoot)
+		for _, pkg := range pkgs {
+			if want := filepath.Join(modRoot, filepath.FromSlash(pkg.Rel)); pkg.Dir != want {
+				t.Errorf("coverDiffPackages() Dir = %v, want %v", pkg.Dir, want)
+			}
+			got[rel] = append(got[rel], pkg.Rel)
+		}
+	}
+	want := map[string][]string{
+		".":   {".", "foo", "foo/with space"},
+		"sub": {".", "pkg"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coverDiffPackages() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffCoverage(t *testing.T) {
+	files := map[string]*cover.Profile{
+		"/src/foo/foo.go": {
+			FileName: "foo.org/foo/foo.go",
+			Mode:     "set",
+			Blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 12, EndLine: 5, EndCol: 5, NumStmt: 2, Count: 1},
+				{StartLine: 5, StartCol: 5, EndLine: 9, EndCol: 5, NumStmt: 3, Count: 0},
+				{StartLine: 12, StartCol: 12, EndLine: 14, EndCol: 2, NumStmt: 1, Count: 0},
+			},
+		},
+	}
+
+	type args struct {
+		changed map[string][]int
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantCovered int
+		wantTotal   int
+		wantErrlist []*nvim.QuickfixError
+	}{
+		{
+			name: "group consecutive uncovered lines",
+			args: args{changed: map[string][]int{
+				"/src/foo/foo.go": {4, 6, 7, 8, 10, 13},
+				"/src/foo/bar.go": {1, 2},
+			}},
+			wantCovered: 1,
+			wantTotal:   5,
+			wantErrlist: []*nvim.QuickfixError{
+				{FileName: "/src/foo/foo.go", LNum: 6, Col: 1, Text: "uncovered changed lines 6-8"},
+				{FileName: "/src/foo/foo.go", LNum: 13, Col: 1, Text: "uncovered changed line 13"},
+			},
+		},
+		{
+			name: "all covered",
+			args: args{changed: map[string][]int{
+				"/src/foo/foo.go": {3, 4},
+			}},
+			wantCovered: 2,
+			wantTotal:   2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			covered, total, errlist := diffCoverage(tt.args.changed, files)
+			if covered != tt.wantCovered || total != tt.wantTotal {
+				t.Errorf("diffCoverage() = %d/%d, want %d/%d", covered, total, tt.wantCovered, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(errlist, tt.wantErrlist) {
+				t.Errorf("diffCoverage() errlist = %v, want %v", errlist, tt.wantErrlist)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"nvim-go/internal/cover"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoCoverHTML
+
+func (c *Command) cmdCoverHTML(args []string, bang bool, eval *cmdCoverEval) {
+	go func() {
+		err := c.CoverHTML(args, bang, eval)
+
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Cover", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// CoverHTML runs the coverage profiling of the current package, or all
+// packages of the repository if bang is true, and writes the coverage HTML
+// such as "go tool cover -html" to the args[0] file.
+// The default output file is "nvim-go-cover-<package name>.html" in the temporary directory.
+func (c *Command) CoverHTML(args []string, bang bool, eval *cmdCoverEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoCoverHTML")
+
+	dir := filepath.Dir(eval.File)
+	files, errlist, err := c.coverPackages(bang, dir)
+	switch {
+	case err != nil:
+		return err
+	case errlist != nil:
+		return errlist
+	}
+	c.errs.Delete("Cover")
+
+	htmlFiles, err := coverHTMLFiles(files)
+	if err != nil {
+		return err
+	}
+
+	out := filepath.Join(os.TempDir(), fmt.Sprintf("nvim-go-cover-%s.html", filepath.Base(dir)))
+	if len(args) > 0 {
+		out = args[0]
+		if !filepath.IsAbs(out) {
+			out = filepath.Join(eval.Cwd, out)
+		}
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := cover.WriteHTML(f, htmlFiles); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nvimutil.Echomsg(c.Nvim, "GoCoverHTML: wrote", out)
+}
+
+// coverHTMLFiles reads the source of files, and returns the HTML files sorted by the profile file name.
+func coverHTMLFiles(files map[string]*cover.Profile) ([]cover.HTMLFile, error) {
+	htmlFiles := make([]cover.HTMLFile, 0, len(files))
+	for file, prof := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		htmlFiles = append(htmlFiles, cover.HTMLFile{Name: prof.FileName, Src: src, Profile: prof})
+	}
+	sort.Slice(htmlFiles, func(i, j int) bool { return htmlFiles[i].Name < htmlFiles[j].Name })
+
+	return htmlFiles, nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/cover"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoCoverReport
+
+// coverFunc represents the statement coverage of a function.
+type coverFunc struct {
+	Name    string
+	Line    int
+	Covered int
+	Total   int
+}
+
+// coverFileReport represents the statement coverage of a file and its functions.
+type coverFileReport struct {
+	// File full path of the file.
+	File string
+	// Name import path of the file that written in the profile.
+	Name    string
+	Covered int
+	Total   int
+	Funcs   []*coverFunc
+}
+
+// coverReportLine represents a line of the coverage report buffer.
+type coverReportLine struct {
+	File string
+	Line int
+}
+
+var (
+	coverReportMu sync.Mutex
+	// lastCoverReport results of the last GoCoverReport.
+	lastCoverReport []*coverFileReport
+	// coverReportSort sort order of the coverage report buffer. "name" or "coverage".
+	coverReportSort = "name"
+	// coverReportLines each line of the coverage report buffer.
+	coverReportLines []*coverReportLine
+
+	coverReportBuf = newScratchBuffer("__GO_COVER_REPORT__", nvimutil.FiletypeGoCover, "botright split")
+)
+
+func (c *Command) cmdCoverReport(bang bool, eval *cmdCoverEval) {
+	go func() {
+		err := c.CoverReport(bang, eval)
+
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Cover", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// CoverReport runs the coverage profiling of the current package, or all
+// packages of the repository if bang is true, and opens the per-file and
+// per-function coverage report buffer.
+func (c *Command) CoverReport(bang bool, eval *cmdCoverEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoCoverReport")
+
+	files, errlist, err := c.coverPackages(bang, filepath.Dir(eval.File))
+	switch {
+	case err != nil:
+		return err
+	case errlist != nil:
+		return errlist
+	}
+	c.errs.Delete("Cover")
+
+	report, err := newCoverReport(files)
+	if err != nil {
+		return err
+	}
+
+	if err := c.setCoverage(files, eval.File); err != nil {
+		return err
+	}
+
+	coverReportMu.Lock()
+	lastCoverReport = report
+	coverReportMu.Unlock()
+
+	rpc := func(action string) string {
+		return fmt.Sprintf(":<C-u>call rpcrequest(%d, 'GoCoverReportAction', '%s', line('.'))<CR>", config.ChannelID, action)
+	}
+	nnoremap := map[string]string{
+		"<CR>": rpc("open"),
+		"s":    rpc("sort"),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := coverReportBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+
+	return c.refreshCoverReport()
+}
+
+// coverPackages runs the coverage profiling of the dir package, or all packages
+// of the repository if all is true, and returns the profile of each file full path.
+func (c *Command) coverPackages(all bool, dir string) (map[string]*cover.Profile, []*nvim.QuickfixError, error) {
+	if !all {
+		profiles, errlist, err := c.coverProfile(dir, []string{"."}, config.CoverMode)
+		if err != nil || errlist != nil {
+			return nil, errlist, err
+		}
+		return coverFiles(profiles, func(string) (string, bool) { return dir, true }), nil, nil
+	}
+
+	roots := []string{pathutil.FindVCSRoot(dir)}
+	if c.ctx.Build.Tool == "mod" {
+		roots = c.ctx.Build.ModuleRoots()
+	}
+	var profiles []*cover.Profile
+	for _, root := range roots {
+		profs, errlist, err := c.coverProfile(root, []string{"./..."}, config.CoverMode)
+		if err != nil || errlist != nil {
+			return nil, errlist, err
+		}
+		profiles = append(profiles, profs...)
+	}
+
+	return coverFiles(profiles, c.testPackageDir), nil, nil
+}
+
+// newCoverReport computes the per-file and per-function coverage of files.
+func newCoverReport(files map[string]*cover.Profile) ([]*coverFileReport, error) {
+	var report []*coverFileReport
+	for file, prof := range files {
+		funcs, err := coverFuncs(file, prof)
+		if err != nil {
+			return nil, err
+		}
+
+		r := &coverFileReport{File: file, Name: prof.FileName, Funcs: funcs}
+		for _, block := range prof.Blocks {
+			r.Total += block.NumStmt
+			if block.Count > 0 {
+				r.Covered += block.NumStmt
+			}
+		}
+		report = append(report, r)
+	}
+
+	return report, nil
+}
+
+// coverFuncs maps the profile blocks onto the function declarations of file,
+// and returns the statement coverage of each function.
+func coverFuncs(file string, prof *cover.Profile) ([]*coverFunc, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var funcs []*coverFunc
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+
+		cf := &coverFunc{Name: funcDeclName(fn), Line: start.Line}
+		for _, block := range prof.Blocks {
+			if block.StartLine < start.Line || (block.StartLine == start.Line && block.StartCol < start.Column) {
+				continue
+			}
+			if block.EndLine > end.Line || (block.EndLine == end.Line && block.EndCol > end.Column) {
+				continue
+			}
+			cf.Total += block.NumStmt
+			if block.Count > 0 {
+				cf.Covered += block.NumStmt
+			}
+		}
+		funcs = append(funcs, cf)
+	}
+
+	return funcs, nil
+}
+
+// funcDeclName returns the function name with the receiver type such as "(*T).Name".
+func funcDeclName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		if ident, ok := star.X.(*ast.Ident); ok {
+			return fmt.Sprintf("(*%s).%s", ident.Name, fn.Name.Name)
+		}
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return fmt.Sprintf("%s.%s", ident.Name, fn.Name.Name)
+	}
+	return fn.Name.Name
+}
+
+// coverPercent returns the percentage of the covered statements.
+func coverPercent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// renderCoverReport renders the coverage report sorted by order, and returns
+// the buffer lines and the jump destination of each line.
+// The order is "name" or "coverage" that sorts by the ascending coverage percentage.
+func renderCoverReport(report []*coverFileReport, order string) ([]string, []*coverReportLine) {
+	files := make([]*coverFileReport, len(report))
+	copy(files, report)
+
+	var covered, total int
+	for _, f := range files {
+		covered += f.Covered
+		total += f.Total
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	if order == "coverage" {
+		sort.SliceStable(files, func(i, j int) bool {
+			return coverPercent(files[i].Covered, files[i].Total) < coverPercent(files[j].Covered, files[j].Total)
+		})
+	}
+
+	lines := []string{fmt.Sprintf("GoCoverReport: %.1f%% of statements (sort: %s)", coverPercent(covered, total), order)}
+	infos := []*coverReportLine{nil}
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("%6.1f%%  %s", coverPercent(f.Covered, f.Total), f.Name))
+		infos = append(infos, &coverReportLine{File: f.File, Line: 1})
+
+		funcs := make([]*coverFunc, len(f.Funcs))
+		copy(funcs, f.Funcs)
+		if order == "coverage" {
+			sort.SliceStable(funcs, func(i, j int) bool {
+				return coverPercent(funcs[i].Covered, funcs[i].Total) < coverPercent(funcs[j].Covered, funcs[j].Total)
+			})
+		}
+		for _, fn := range funcs {
+			lines = append(lines, fmt.Sprintf("  %6.1f%%  %s:%d", coverPercent(fn.Covered, fn.Total), fn.Name, fn.Line))
+			infos = append(infos, &coverReportLine{File: f.File, Line: fn.Line})
+		}
+	}
+
+	return lines, infos
+}
+
+// refreshCoverReport re-renders the coverage report buffer if opened.
+func (c *Command) refreshCoverReport() error {
+	if !coverReportBuf.isValid(c) {
+		return nil
+	}
+
+	coverReportMu.Lock()
+	var lines []string
+	lines, coverReportLines = renderCoverReport(lastCoverReport, coverReportSort)
+	coverReportMu.Unlock()
+
+	return coverReportBuf.setLines(c, lines)
+}
+
+// coverReportAction handles the mapping action of the coverage report buffer.
+func (c *Command) coverReportAction(action string, line int) error {
+	switch action {
+	case "sort":
+		coverReportMu.Lock()
+		if coverReportSort == "name" {
+			coverReportSort = "coverage"
+		} else {
+			coverReportSort = "name"
+		}
+		coverReportMu.Unlock()
+		return c.refreshCoverReport()
+
+	case "open":
+		coverReportMu.Lock()
+		var l *coverReportLine
+		if line >= 1 && line <= len(coverReportLines) {
+			l = coverReportLines[line-1]
+		}
+		coverReportMu.Unlock()
+		if l == nil {
+			return nil
+		}
+
+		batch := c.Nvim.NewBatch()
+		batch.Command("wincmd p")
+		batch.Command(fmt.Sprintf("execute 'edit' fnameescape(%s)", strconv.Quote(l.File)))
+		batch.Command(fmt.Sprintf("call cursor(%d, 1)", l.Line))
+		batch.Command("normal! zz")
+		if err := batch.Execute(); err != nil {
+			return errors.WithStack(err)
+		}
+
+		b, err := c.Nvim.CurrentBuffer()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return c.CoverBufEnter(int(b), l.File)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nvim-go/internal/cover"
+)
+
+const coverReportSrc = `package foo
+
+type T struct{}
+
+func (t *T) Foo(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func Bar() {
+	println("bar")
+}
+`
+
+func TestCoverFuncs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-cover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "foo.go")
+	if err := ioutil.WriteFile(file, []byte(coverReportSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prof := &cover.Profile{
+		FileName: "foo.org/foo/foo.go",
+		Mode:     "set",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 5, StartCol: 29, EndLine: 6, EndCol: 7, NumStmt: 1, Count: 1},
+			{StartLine: 6, StartCol: 7, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 0},
+			{StartLine: 9, StartCol: 2, EndLine: 9, EndCol: 10, NumStmt: 1, Count: 1},
+			{StartLine: 12, StartCol: 12, EndLine: 14, EndCol: 2, NumStmt: 1, Count: 0},
+		},
+	}
+
+	got, err := coverFuncs(file, prof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*coverFunc{
+		{Name: "(*T).Foo", Line: 5, Covered: 2, Total: 3},
+		{Name: "Bar", Line: 12, Covered: 0, Total: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coverFuncs() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderCoverReport(t *testing.T) {
+	report := []*coverFileReport{
+		{
+			File: "/src/foo/foo.go", Name: "foo.org/foo/foo.go", Covered: 3, Total: 4,
+			Funcs: []*coverFunc{
+				{Name: "Foo", Line: 3, Covered: 3, Total: 3},
+				{Name: "Bar", Line: 9, Covered: 0, Total: 1},
+			},
+		},
+		{
+			File: "/src/foo/bar.go", Name: "foo.org/foo/bar.go", Covered: 1, Total: 1,
+			Funcs: []*coverFunc{
+				{Name: "Baz", Line: 5, Covered: 1, Total: 1},
+			},
+		},
+	}
+
+	type args struct {
+		order string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantLines []string
+		wantInfos []*coverReportLine
+	}{
+		{
+			name: "name",
+			args: args{order: "name"},
+			wantLines: []string{
+				"GoCoverReport: 80.0% of statements (sort: name)",
+				" 100.0%  foo.org/foo/bar.go",
+				"   100.0%  Baz:5",
+				"  75.0%  foo.org/foo/foo.go",
+				"   100.0%  Foo:3",
+				"     0.0%  Bar:9",
+			},
+			wantInfos: []*coverReportLine{
+				nil,
+				{File: "/src/foo/bar.go", Line: 1},
+				{File: "/src/foo/bar.go", Line: 5},
+				{File: "/src/foo/foo.go", Line: 1},
+				{File: "/src/foo/foo.go", Line: 3},
+				{File: "/src/foo/foo.go", Line: 9},
+			},
+		},
+		{
+			name: "coverage",
+			args: args{order: "coverage"},
+			wantLines: []string{
+				"GoCoverReport: 80.0% of statements (sort: coverage)",
+				"  75.0%  foo.org/foo/foo.go",
+				"     0.0%  Bar:9",
+				"   100.0%  Foo:3",
+				" 100.0%  foo.org/foo/bar.go",
+				"   100.0%  Baz:5",
+			},
+			wantInfos: []*coverReportLine{
+				nil,
+				{File: "/src/foo/foo.go", Line: 1},
+				{File: "/src/foo/foo.go", Line: 9},
+				{File: "/src/foo/foo.go", Line: 3},
+				{File: "/src/foo/bar.go", Line: 1},
+				{File: "/src/foo/bar.go", Line: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lines, infos := renderCoverReport(report, tt.args.order)
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("renderCoverReport(%v) lines = %q, want %q", tt.args.order, lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(infos, tt.wantInfos) {
+				t.Errorf("renderCoverReport(%v) infos = %v, want %v", tt.args.order, infos, tt.wantInfos)
+			}
+		})
+	}
+}
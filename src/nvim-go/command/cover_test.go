@@ -0,0 +1,121 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"nvim-go/internal/cover"
+)
+
+func TestCoverLines(t *testing.T) {
+	type args struct {
+		prof *cover.Profile
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[int]string
+	}{
+		{
+			name: "hit and miss",
+			args: args{prof: &cover.Profile{
+				FileName: "foo/foo.go",
+				Mode:     "set",
+				Blocks: []cover.ProfileBlock{
+					{StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 2},
+					{StartLine: 7, StartCol: 14, EndLine: 8, EndCol: 10, NumStmt: 1, Count: 0},
+				},
+			}},
+			want: map[int]string{
+				2: "GoCoverHit",
+				3: "GoCoverHit",
+				6: "GoCoverMiss",
+				7: "GoCoverMiss",
+			},
+		},
+		{
+			name: "partial and first block wins",
+			args: args{prof: &cover.Profile{
+				FileName: "foo/foo.go",
+				Mode:     "set",
+				Blocks: []cover.ProfileBlock{
+					{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 5, NumStmt: 1, Count: 1},
+					{StartLine: 2, StartCol: 5, EndLine: 3, EndCol: 5, NumStmt: 2, Count: 0},
+				},
+			}},
+			want: map[int]string{
+				0: "GoCoverPartial",
+				1: "GoCoverPartial",
+				2: "GoCoverMiss",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := coverLines(tt.args.prof); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coverLines(%v) = %v, want %v", tt.args.prof, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverFiles(t *testing.T) {
+	profiles := []*cover.Profile{
+		{FileName: "foo.org/foo/foo.go"},
+		{FileName: "foo.org/foo/bar/bar.go"},
+		{FileName: "foo.org/baz/baz.go"},
+	}
+	pkgDir := func(importPath string) (string, bool) {
+		switch importPath {
+		case "foo.org/foo":
+			return "/src/foo", true
+		case "foo.org/foo/bar":
+			return "/src/foo/bar", true
+		}
+		return "", false
+	}
+
+	got := coverFiles(profiles, pkgDir)
+	want := map[string]*cover.Profile{
+		"/src/foo/foo.go":     profiles[0],
+		"/src/foo/bar/bar.go": profiles[1],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coverFiles(%v) = %v, want %v", profiles, got, want)
+	}
+}
+
+func TestCoverHeatLines(t *testing.T) {
+	prof := &cover.Profile{
+		FileName: "foo/foo.go",
+		Mode:     "count",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 14, EndLine: 4, EndCol: 10, NumStmt: 1, Count: 100},
+			{StartLine: 5, StartCol: 2, EndLine: 5, EndCol: 10, NumStmt: 1, Count: 10},
+			{StartLine: 6, StartCol: 2, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+			{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 10, NumStmt: 1, Count: 0},
+		},
+	}
+
+	lines, counts := coverHeatLines(prof)
+	wantLines := map[int]string{
+		2: "GoCoverHeat10",
+		3: "GoCoverHeat10",
+		4: "GoCoverHeat5",
+		5: "GoCoverHeat1",
+		7: "GoCoverMiss",
+	}
+	if !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("coverHeatLines() lines = %v, want %v", lines, wantLines)
+	}
+	wantCounts := map[int]int{2: 100, 4: 10, 5: 1, 7: 0}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("coverHeatLines() counts = %v, want %v", counts, wantCounts)
+	}
+}
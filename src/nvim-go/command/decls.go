@@ -0,0 +1,346 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoDecls, GoDeclsDir
+
+// CmdDeclsEval struct type for Eval of GoDecls and GoDeclsDir commands.
+type CmdDeclsEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	BufNr    int
+}
+
+// decl represents a top-level declaration.
+type decl struct {
+	// Kind kind of the declaration. "func", "method", "type", "const" or "var".
+	Kind string
+	// Name name of the declaration. The method name has the receiver such as "(*T).Method".
+	Name string
+	Pos  token.Position
+}
+
+var (
+	declsMu sync.Mutex
+	// lastDecls all declarations of the last GoDecls or GoDeclsDir.
+	lastDecls []*decl
+	// declsTitle title of the picker buffer such as "GoDecls: foo.go".
+	declsTitle string
+	// declsFilter fuzzy filter pattern of the picker buffer.
+	declsFilter string
+	// declsCwd current directory for the relative path of the picker buffer.
+	declsCwd string
+	// declsLines the declaration of each line of the picker buffer. The first line is the header.
+	declsLines []*decl
+
+	declsBuf = newScratchBuffer("__GO_DECLS__", nvimutil.FiletypeGoDecls, "botright split")
+)
+
+func (c *Command) cmdDecls(args []string, bang bool, eval *CmdDeclsEval) {
+	go func() {
+		if err := c.Decls(args, bang, false, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+func (c *Command) cmdDeclsDir(args []string, bang bool, eval *CmdDeclsEval) {
+	go func() {
+		if err := c.Decls(args, bang, true, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Decls lists the top-level declarations of the current file, or the all Go
+// files in the directory of the current file if dir is true.
+// The declarations are shown in the picker buffer that filtered by args[0] as
+// the fuzzy pattern, or set to the location list if bang is true.
+func (c *Command) Decls(args []string, bang, dir bool, eval *CmdDeclsEval) error {
+	name := "GoDecls"
+	if dir {
+		name = "GoDeclsDir"
+	}
+	defer nvimutil.Profile(time.Now(), name)
+
+	// parse the buffer contents instead of the file if modified
+	var src []byte
+	if eval.Modified != 0 || !dir {
+		buf, err := c.Nvim.BufferLines(nvim.Buffer(eval.BufNr), 0, -1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		src = bytes.Join(buf, []byte{'\n'})
+	}
+
+	var (
+		decls []*decl
+		title string
+	)
+	if dir {
+		d, err := parseDirDecls(filepath.Dir(eval.File), map[string][]byte{eval.File: src})
+		if err != nil {
+			return err
+		}
+		decls = d
+		title = fmt.Sprintf("%s: %s", name, pathutil.Rel(eval.Cwd, filepath.Dir(eval.File)))
+	} else {
+		d, err := parseDecls(eval.File, src)
+		if err != nil {
+			return err
+		}
+		decls = d
+		title = fmt.Sprintf("%s: %s", name, pathutil.Rel(eval.Cwd, eval.File))
+	}
+
+	var filter string
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	if bang {
+		var loclist []*nvim.QuickfixError
+		for _, d := range filterDecls(decls, filter) {
+			loclist = append(loclist, &nvim.QuickfixError{
+				FileName: pathutil.Rel(eval.Cwd, d.Pos.Filename),
+				LNum:     d.Pos.Line,
+				Col:      d.Pos.Column,
+				Text:     d.Kind + " " + d.Name,
+			})
+		}
+		if err := nvimutil.SetLoclist(c.Nvim, loclist); err != nil {
+			return errors.WithStack(err)
+		}
+		w, err := c.Nvim.CurrentWindow()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return nvimutil.OpenLoclist(c.Nvim, w, loclist, true)
+	}
+
+	declsMu.Lock()
+	lastDecls, declsTitle, declsFilter, declsCwd = decls, title, filter, eval.Cwd
+	declsMu.Unlock()
+
+	rpc := func(action, filter string) string {
+		return fmt.Sprintf(":<C-u>call rpcrequest(%d, 'GoDeclsAction', '%s', line('.'), %s)<CR>", config.ChannelID, action, filter)
+	}
+	nnoremap := map[string]string{
+		"<CR>": rpc("jump", "''"),
+		"f":    rpc("filter", "input('GoDecls filter: ')"),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := declsBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+
+	return c.refreshDecls()
+}
+
+// parseDecls parses the file, or src if not nil, and returns its top-level declarations.
+// The declarations are returned even if the file has the syntax error such as the editing buffer.
+func parseDecls(filename string, src []byte) ([]*decl, error) {
+	var s interface{}
+	if src != nil {
+		s = src
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, s, 0)
+	if f == nil || (err != nil && len(f.Decls) == 0) {
+		return nil, errors.WithStack(err)
+	}
+
+	var decls []*decl
+	add := func(kind, name string, pos token.Pos) {
+		if name == "_" {
+			return
+		}
+		decls = append(decls, &decl{Kind: kind, Name: name, Pos: fset.Position(pos)})
+	}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			kind := "func"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			add(kind, funcDeclName(d), d.Name.Pos())
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add("type", spec.Name.Name, spec.Name.Pos())
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						add(d.Tok.String(), id.Name, id.Pos())
+					}
+				}
+			}
+		}
+	}
+
+	return decls, nil
+}
+
+// parseDirDecls parses the all Go files in dir and returns their top-level
+// declarations sorted by the filename. The overlay contents are parsed
+// instead of the file if not nil, such as the modified buffer.
+// The unparseable file is skipped, and returns the first error only if the
+// all files are unparseable.
+func parseDirDecls(dir string, overlay map[string][]byte) ([]*decl, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(files)
+
+	var (
+		decls    []*decl
+		firstErr error
+		parsed   int
+	)
+	for _, file := range files {
+		d, err := parseDecls(file, overlay[file])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		parsed++
+		decls = append(decls, d...)
+	}
+	if parsed == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	return decls, nil
+}
+
+// filterDecls returns the decls whose name matches to the fuzzy pattern.
+func filterDecls(decls []*decl, pattern string) []*decl {
+	if pattern == "" {
+		return decls
+	}
+
+	var filtered []*decl
+	for _, d := range decls {
+		if fuzzyMatch(d.Name, pattern) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// fuzzyMatch reports whether the all characters of pattern appear in s in
+// the same order, ignoring case.
+func fuzzyMatch(s, pattern string) bool {
+	s, pattern = strings.ToLower(s), strings.ToLower(pattern)
+	for _, r := range pattern {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// renderDecls renders the picker buffer lines of decls, and returns the lines
+// and the declaration of each line.
+func renderDecls(title, filter, cwd string, decls []*decl) ([]string, []*decl) {
+	header := title
+	if filter != "" {
+		header += " /" + filter
+	}
+	lines := []string{header}
+	declsOfLine := []*decl{nil}
+
+	width := 0
+	for _, d := range decls {
+		if n := len(d.Name); n > width {
+			width = n
+		}
+	}
+	for _, d := range decls {
+		lines = append(lines, fmt.Sprintf("%-6s %-*s  %s:%d:%d", d.Kind, width, d.Name, pathutil.Rel(cwd, d.Pos.Filename), d.Pos.Line, d.Pos.Column))
+		declsOfLine = append(declsOfLine, d)
+	}
+
+	return lines, declsOfLine
+}
+
+// refreshDecls re-renders the picker buffer with the current filter if opened.
+func (c *Command) refreshDecls() error {
+	if !declsBuf.isValid(c) {
+		return nil
+	}
+
+	declsMu.Lock()
+	var lines []string
+	lines, declsLines = renderDecls(declsTitle, declsFilter, declsCwd, filterDecls(lastDecls, declsFilter))
+	declsMu.Unlock()
+
+	return declsBuf.setLines(c, lines)
+}
+
+// declsAction handles the mapping action of the picker buffer.
+// The filter is the new fuzzy pattern of the "filter" action.
+func (c *Command) declsAction(action string, line int, filter string) error {
+	switch action {
+	case "jump":
+		declsMu.Lock()
+		var d *decl
+		if line >= 1 && line <= len(declsLines) {
+			d = declsLines[line-1]
+		}
+		declsMu.Unlock()
+		if d == nil {
+			return nil
+		}
+
+		// close the picker and jump to the declaration on the previous window
+		batch := c.Nvim.NewBatch()
+		batch.Command("wincmd p")
+		batch.Command(fmt.Sprintf("silent! %dbwipeout", declsBuf.Buffer.Buffer()))
+		batch.Command(fmt.Sprintf("execute 'edit' fnameescape(%s)", strconv.Quote(d.Pos.Filename)))
+		batch.Command(fmt.Sprintf("call cursor(%d, %d)", d.Pos.Line, d.Pos.Column))
+		batch.Command("normal! zz")
+		return errors.WithStack(batch.Execute())
+
+	case "filter":
+		declsMu.Lock()
+		declsFilter = filter
+		declsMu.Unlock()
+		return c.refreshDecls()
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var declsTestSrc = []byte(`package foo
+
+import "fmt"
+
+const (
+	Foo = 1
+	_   = 2
+)
+
+var bar, baz = fmt.Sprint(), 0
+
+type T struct{}
+
+func (T) Value() {}
+
+func (t *T) Pointer() {}
+
+func New() *T { return &T{} }
+`)
+
+func TestParseDecls(t *testing.T) {
+	type args struct {
+		src []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all kinds",
+			args: args{src: declsTestSrc},
+			want: []string{
+				"const Foo 6:2",
+				"var bar 10:5",
+				"var baz 10:10",
+				"type T 12:6",
+				"method T.Value 14:10",
+				"method (*T).Pointer 16:13",
+				"func New 18:6",
+			},
+		},
+		{
+			name: "syntax error",
+			args: args{src: []byte("package foo\n\nfunc Foo() {}\n\nfunc Bar( {\n")},
+			want: []string{"func Foo 3:6", "func Bar 5:6"},
+		},
+		{
+			name:    "no package clause",
+			args:    args{src: []byte("func Foo() {}\n")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			decls, err := parseDecls("foo.go", tt.args.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDecls(%s) error = %v, wantErr %v", tt.args.src, err, tt.wantErr)
+				return
+			}
+			var got []string
+			for _, d := range decls {
+				got = append(got, d.Kind+" "+d.Name+" "+token.Position{Line: d.Pos.Line, Column: d.Pos.Column}.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDecls(%s) = %v, want %v", tt.args.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDirDecls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim-go-decls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.go":      "package foo\n\nfunc A() {}\n",
+		"broken.go": "func Broken() {}\n",
+		"c.go":      "package foo\n\ntype C struct{}\n",
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type args struct {
+		overlay map[string][]byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "skip unparseable file",
+			args: args{overlay: nil},
+			want: []string{"func A a.go", "type C c.go"},
+		},
+		{
+			name: "overlay",
+			args: args{overlay: map[string][]byte{filepath.Join(dir, "broken.go"): []byte("package foo\n\nvar B int\n")}},
+			want: []string{"func A a.go", "var B broken.go", "type C c.go"},
+		},
+		{
+			name: "all unparseable",
+			args: args{overlay: map[string][]byte{
+				filepath.Join(dir, "a.go"): []byte("func A() {}\n"),
+				filepath.Join(dir, "c.go"): []byte("type C struct{}\n"),
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			decls, err := parseDirDecls(dir, tt.args.overlay)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDirDecls(%v) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+				return
+			}
+			var got []string
+			for _, d := range decls {
+				got = append(got, d.Kind+" "+d.Name+" "+filepath.Base(d.Pos.Filename))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDirDecls(%v) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	type args struct {
+		s       string
+		pattern string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{name: "empty pattern", args: args{s: "NewCommand", pattern: ""}, want: true},
+		{name: "prefix", args: args{s: "NewCommand", pattern: "New"}, want: true},
+		{name: "subsequence", args: args{s: "NewCommand", pattern: "ncmd"}, want: true},
+		{name: "receiver", args: args{s: "(*T).Pointer", pattern: "tptr"}, want: true},
+		{name: "wrong order", args: args{s: "NewCommand", pattern: "dn"}, want: false},
+		{name: "not contained", args: args{s: "NewCommand", pattern: "x"}, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := fuzzyMatch(tt.args.s, tt.args.pattern); got != tt.want {
+				t.Errorf("fuzzyMatch(%v, %v) = %v, want %v", tt.args.s, tt.args.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDecls(t *testing.T) {
+	decls := []*decl{
+		{Kind: "type", Name: "T", Pos: token.Position{Filename: "/src/foo/foo.go", Line: 12, Column: 6}},
+		{Kind: "method", Name: "(*T).Pointer", Pos: token.Position{Filename: "/src/foo/foo.go", Line: 16, Column: 13}},
+		{Kind: "func", Name: "New", Pos: token.Position{Filename: "/src/foo/new.go", Line: 3, Column: 6}},
+	}
+
+	type args struct {
+		filter string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "no filter",
+			args: args{filter: ""},
+			want: []string{
+				"GoDeclsDir: .",
+				"type   T             foo.go:12:6",
+				"method (*T).Pointer  foo.go:16:13",
+				"func   New           new.go:3:6",
+			},
+		},
+		{
+			name: "filtered",
+			args: args{filter: "new"},
+			want: []string{
+				"GoDeclsDir: . /new",
+				"func   New  new.go:3:6",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, declsOfLine := renderDecls("GoDeclsDir: .", tt.args.filter, "/src/foo", filterDecls(decls, tt.args.filter))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderDecls(%v) = %q, want %q", tt.args.filter, got, tt.want)
+			}
+			if len(declsOfLine) != len(got) || declsOfLine[0] != nil {
+				t.Errorf("renderDecls(%v) decls = %v, want the declaration of each line", tt.args.filter, declsOfLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoDefPop, GoDefStack, GoDefStackClear
+
+// defStackEntry represents a jump of the GoGuruDefinition.
+type defStackEntry struct {
+	// From cursor position before the jump.
+	From token.Position
+	// Desc description of the definition object such as "func foo.Bar".
+	Desc string
+}
+
+// defStack represents the definition jump stack of a window.
+// The entries after the index are popped entries, those are discarded by the next jump.
+type defStack struct {
+	entries []*defStackEntry
+	index   int
+}
+
+// push pushes the e to the stack, and discards the popped entries.
+func (s *defStack) push(e *defStackEntry) {
+	s.entries = append(s.entries[:s.index], e)
+	s.index = len(s.entries)
+}
+
+// pop pops the n entries, and returns the jump back position.
+func (s *defStack) pop(n int) (token.Position, bool) {
+	if s.index == 0 {
+		return token.Position{}, false
+	}
+	if n < 1 {
+		n = 1
+	}
+	s.index -= n
+	if s.index < 0 {
+		s.index = 0
+	}
+	return s.entries[s.index].From, true
+}
+
+var (
+	defStackMu sync.Mutex
+	// defStacks definition jump stack of each window.
+	defStacks = make(map[nvim.Window]*defStack)
+
+	defStackBuf = newScratchBuffer("__GO_DEF_STACK__", nvimutil.FiletypeGoDefStack, "botright split")
+	// defStackWin window of the opened definition stack buffer.
+	defStackWin nvim.Window
+)
+
+// pushDefStack pushes the definition jump of the w window.
+func pushDefStack(w nvim.Window, e *defStackEntry) {
+	defStackMu.Lock()
+	defer defStackMu.Unlock()
+
+	s, ok := defStacks[w]
+	if !ok {
+		s = new(defStack)
+		defStacks[w] = s
+	}
+	s.push(e)
+}
+
+// defJump jumps to pos in the w window.
+func (c *Command) defJump(w nvim.Window, pos token.Position) error {
+	file := strconv.Quote(pos.Filename)
+
+	batch := c.Nvim.NewBatch()
+	batch.SetCurrentWindow(w)
+	batch.Command(fmt.Sprintf("if expand('%%:p') !=# %s | execute 'keepjumps edit' fnameescape(%s) | endif", file, file))
+	batch.SetWindowCursor(w, [2]int{pos.Line, pos.Column - 1})
+	batch.Command("normal! zz")
+
+	return errors.WithStack(batch.Execute())
+}
+
+func (c *Command) cmdDefPop(args []string, winID int) {
+	go func() {
+		if err := c.DefPop(args, winID); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// DefPop jumps back to the position before the last args[0] (default 1) definition jumps of the window.
+func (c *Command) DefPop(args []string, winID int) error {
+	defer nvimutil.Profile(time.Now(), "GoDefPop")
+
+	n := 1
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			return errors.Wrapf(err, "invalid count: %s", args[0])
+		}
+	}
+
+	w := nvim.Window(winID)
+	defStackMu.Lock()
+	var (
+		pos token.Position
+		ok  bool
+	)
+	if s, found := defStacks[w]; found {
+		pos, ok = s.pop(n)
+	}
+	defStackMu.Unlock()
+	if !ok {
+		return nvimutil.Echoerr(c.Nvim, "GoDefPop: at bottom of the definition stack")
+	}
+
+	if err := c.defJump(w, pos); err != nil {
+		return err
+	}
+	return c.refreshDefStack()
+}
+
+func (c *Command) cmdDefStack(winID int) {
+	go func() {
+		if err := c.DefStack(winID); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// DefStack opens the definition stack buffer of the window.
+// <CR> on the entry jumps back to the position before the jump of the entry.
+func (c *Command) DefStack(winID int) error {
+	defer nvimutil.Profile(time.Now(), "GoDefStack")
+
+	defStackMu.Lock()
+	defStackWin = nvim.Window(winID)
+	defStackMu.Unlock()
+
+	nnoremap := map[string]string{
+		"<CR>": fmt.Sprintf(":<C-u>call rpcrequest(%d, 'GoDefStackJump', line('.'))<CR>", config.ChannelID),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := defStackBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+
+	return c.refreshDefStack()
+}
+
+// refreshDefStack re-renders the definition stack buffer if opened.
+func (c *Command) refreshDefStack() error {
+	if !defStackBuf.isValid(c) {
+		return nil
+	}
+
+	var cwd string
+	if err := c.Nvim.Call("getcwd", &cwd); err != nil {
+		return errors.WithStack(err)
+	}
+
+	defStackMu.Lock()
+	lines := renderDefStack(defStacks[defStackWin], cwd)
+	defStackMu.Unlock()
+
+	return defStackBuf.setLines(c, lines)
+}
+
+// renderDefStack renders the definition stack s. The ">" marks the last jump
+// that the next GoDefPop goes back.
+func renderDefStack(s *defStack, cwd string) []string {
+	if s == nil || len(s.entries) == 0 {
+		return []string{"GoDefStack: empty"}
+	}
+
+	lines := make([]string, len(s.entries))
+	for i, e := range s.entries {
+		mark := " "
+		if i == s.index-1 {
+			mark = ">"
+		}
+		lines[i] = fmt.Sprintf("%s %2d %s:%d:%d  %s", mark, i+1, pathutil.Rel(cwd, e.From.Filename), e.From.Line, e.From.Column, e.Desc)
+	}
+
+	return lines
+}
+
+// defStackJump jumps back to the position before the jump of the line entry, same as the GoDefPop.
+func (c *Command) defStackJump(line int) error {
+	defStackMu.Lock()
+	w := defStackWin
+	s, ok := defStacks[w]
+	if !ok || line < 1 || line > len(s.entries) {
+		defStackMu.Unlock()
+		return nil
+	}
+	s.index = line - 1
+	pos := s.entries[s.index].From
+	defStackMu.Unlock()
+
+	if err := c.defJump(w, pos); err != nil {
+		return err
+	}
+	return c.refreshDefStack()
+}
+
+func (c *Command) cmdDefStackClear(winID int) {
+	go func() {
+		if err := c.DefStackClear(winID); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// DefStackClear clears the definition stack of the window.
+func (c *Command) DefStackClear(winID int) error {
+	defer nvimutil.Profile(time.Now(), "GoDefStackClear")
+
+	defStackMu.Lock()
+	delete(defStacks, nvim.Window(winID))
+	defStackMu.Unlock()
+
+	return c.refreshDefStack()
+}
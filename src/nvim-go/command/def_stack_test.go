@@ -0,0 +1,87 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestDefStack(t *testing.T) {
+	entry := func(line int) *defStackEntry {
+		return &defStackEntry{From: token.Position{Filename: "/src/foo/foo.go", Line: line, Column: 1}, Desc: "func foo.Bar"}
+	}
+
+	s := new(defStack)
+	if _, ok := s.pop(1); ok {
+		t.Errorf("pop() of the empty stack = true, want false")
+	}
+
+	s.push(entry(1))
+	s.push(entry(2))
+	s.push(entry(3))
+	if pos, ok := s.pop(2); !ok || pos.Line != 2 {
+		t.Errorf("pop(2) = %v, %v, want line 2", pos, ok)
+	}
+
+	// the popped entries are discarded by the next jump
+	s.push(entry(4))
+	var got []int
+	for _, e := range s.entries {
+		got = append(got, e.From.Line)
+	}
+	if want := []int{1, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("push() entries = %v, want %v", got, want)
+	}
+
+	if pos, ok := s.pop(10); !ok || pos.Line != 1 || s.index != 0 {
+		t.Errorf("pop(10) = %v, %v, index %d, want line 1 and index 0", pos, ok, s.index)
+	}
+}
+
+func TestRenderDefStack(t *testing.T) {
+	type args struct {
+		s   *defStack
+		cwd string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "empty",
+			args: args{s: nil, cwd: "/src"},
+			want: []string{"GoDefStack: empty"},
+		},
+		{
+			name: "current mark",
+			args: args{
+				s: &defStack{
+					entries: []*defStackEntry{
+						{From: token.Position{Filename: "/src/foo/foo.go", Line: 10, Column: 2}, Desc: "func foo.Bar"},
+						{From: token.Position{Filename: "/src/bar/bar.go", Line: 3, Column: 5}, Desc: "type bar.Baz struct{}"},
+					},
+					index: 1,
+				},
+				cwd: "/src",
+			},
+			want: []string{
+				">  1 foo/foo.go:10:2  func foo.Bar",
+				"   2 bar/bar.go:3:5  type bar.Baz struct{}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := renderDefStack(tt.args.s, tt.args.cwd); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderDefStack() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
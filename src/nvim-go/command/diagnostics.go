@@ -0,0 +1,106 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// CmdDiagnosticsEval struct type for Eval of the live diagnostics autocmd.
+type CmdDiagnosticsEval struct {
+	Cwd   string `msgpack:",array"`
+	File  string
+	BufNr int
+}
+
+// diagnosticsNamespace namespace name of the live diagnostics highlights.
+const diagnosticsNamespace = "nvim-go-diagnostics"
+
+// Diagnostics parses and type-checks the buffer contents of eval.BufNr as the
+// overlay, and publishes the errors to the errorlist and the buffer highlights.
+// The result is discarded if ctx is canceled by the newer edit during the check.
+func (c *Command) Diagnostics(ctx context.Context, eval *CmdDiagnosticsEval) error {
+	defer nvimutil.Profile(time.Now(), "GoDiagnostics")
+
+	b := nvim.Buffer(eval.BufNr)
+	buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	overlay := map[string][]byte{eval.File: bytes.Join(buf, []byte{'\n'})}
+
+	errlist, err := typeCheck(ctx, eval.File, overlay)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.publishDiagnostics(b, eval.File, errlist)
+}
+
+// publishDiagnostics highlights the errors of file in the b buffer with the
+// error message virtual text, and stores the errors as the "Diagnostics" of
+// c.errs and publishes the merged errorlist.
+func (c *Command) publishDiagnostics(b nvim.Buffer, file string, errlist []*nvim.QuickfixError) error {
+	ns, err := nvimutil.CreateNamespace(c.Nvim, diagnosticsNamespace)
+	if err != nil {
+		return err
+	}
+
+	var res int // for ignore the msgpack decode errror. not used
+	batch := c.Nvim.NewBatch()
+	batch.Call("nvim_buf_clear_namespace", nil, int(b), ns, 0, -1)
+	for line, text := range diagnosticsLines(file, errlist) {
+		batch.AddBufferHighlight(b, ns, "GoDiagnosticError", line, 0, -1, &res)
+		chunks := [][]string{{text, "GoDiagnosticMessage"}}
+		batch.Call("nvim_buf_set_virtual_text", &res, int(b), ns, line, chunks, map[string]interface{}{})
+	}
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// merge with the other commands errors such as GoBuild, same as the cmdBuild
+	if len(errlist) == 0 {
+		c.errs.Delete("Diagnostics")
+	} else {
+		c.errs.Store("Diagnostics", errlist)
+	}
+	errs := make(map[string][]*nvim.QuickfixError)
+	c.errs.Range(func(ki, vi interface{}) bool {
+		k, v := ki.(string), vi.([]*nvim.QuickfixError)
+		errs[k] = append(errs[k], v...)
+		return true
+	})
+
+	return nvimutil.ErrorList(c.Nvim, errs, true)
+}
+
+// diagnosticsLines returns the first error message of each line of file.
+// The line number is started by 0 for nvim_buf_add_highlight.
+func diagnosticsLines(file string, errlist []*nvim.QuickfixError) map[int]string {
+	lines := make(map[int]string)
+	for _, e := range errlist {
+		if e.FileName != file {
+			continue
+		}
+		if _, ok := lines[e.LNum-1]; !ok {
+			lines[e.LNum-1] = e.Text
+		}
+	}
+
+	return lines
+}
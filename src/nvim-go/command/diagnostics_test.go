@@ -0,0 +1,50 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestDiagnosticsLines(t *testing.T) {
+	errlist := []*nvim.QuickfixError{
+		{FileName: "/src/foo/foo.go", LNum: 3, Col: 2, Text: "undefined: bar"},
+		{FileName: "/src/foo/foo.go", LNum: 3, Col: 10, Text: "declared and not used: x"},
+		{FileName: "/src/foo/bar.go", LNum: 5, Col: 1, Text: "missing return"},
+		{FileName: "/src/foo/foo.go", LNum: 8, Col: 1, Text: "missing return"},
+	}
+
+	type args struct {
+		file string
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[int]string
+	}{
+		{
+			name: "first message of each line",
+			args: args{file: "/src/foo/foo.go"},
+			want: map[int]string{2: "undefined: bar", 7: "missing return"},
+		},
+		{
+			name: "no errors",
+			args: args{file: "/src/foo/baz.go"},
+			want: map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := diagnosticsLines(tt.args.file, errlist); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diagnosticsLines(%v) = %v, want %v", tt.args.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,7 +11,6 @@ package command
 import (
 	"bytes"
 	"fmt"
-	"go/build"
 	"go/token"
 	"path/filepath"
 	"strings"
@@ -31,6 +30,20 @@ import (
 	"golang.org/x/tools/go/buildutil"
 )
 
+// guruCache caches the loaded analysis scope program across the guru queries.
+var guruCache = guru.NewCache()
+
+// ReloadGuruCache discards the cached guru analysis programs that have the
+// package of the saved file, and reloads their whole scope in the background
+// if config.GuruCache.
+func (c *Command) ReloadGuruCache(file string) {
+	if !config.GuruCache {
+		guruCache.Invalidate(file)
+		return
+	}
+	guruCache.Reload(file)
+}
+
 type funcGuruEval struct {
 	Cwd      string `msgpack:",array"`
 	File     string
@@ -77,7 +90,7 @@ func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 	w := nvim.Window(c.ctx.WinID)
 	batch := c.Nvim.NewBatch()
 
-	guruContext := &build.Default
+	guruContext := buildTagsContext()
 
 	// https://github.com/golang/tools/blob/master/cmd/guru/main.go
 	if eval.Modified != 0 {
@@ -107,6 +120,11 @@ func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 		}
 		fname, line, col := nvimutil.SplitPos(obj.ObjPos, eval.Cwd)
 
+		cursor, err := c.Nvim.WindowCursor(w)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
 		batch.Command("normal! m'")
 		// TODO(zchee): should change nvimutil.SplitPos behavior
 		f := strings.Split(obj.ObjPos, ":")
@@ -118,34 +136,27 @@ func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 			return errors.WithStack(err)
 		}
 
+		pushDefStack(w, &defStackEntry{
+			From: token.Position{Filename: eval.File, Line: cursor[0], Column: cursor[1] + 1},
+			Desc: obj.Desc,
+		})
+		if err := c.refreshDefStack(); err != nil {
+			return err
+		}
+
 		return c.Nvim.Command(`lclose | normal! zz`)
 	}
 
-	var scopes []string
-	switch c.ctx.Build.Tool {
-	case "go":
-		pkgID, err := pathutil.PackageID(filepath.Dir(eval.File))
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		scopes = []string{pathutil.ToWildcard(pkgID)}
-	case "gb":
-		var err error
-		scopes, err = pathutil.GbPackages(c.ctx.Build.ProjectRoot)
-		if err != nil {
-			return errors.Wrap(err, "could not get gb packages")
-		}
-		for i, pkg := range scopes {
-			scopes[i] = pathutil.ToWildcard(pkg)
-		}
-		log.Debug(scopes)
+	scopes, err := c.guruScope(eval.File)
+	if err != nil {
+		return err
 	}
 	query.Scope = append(query.Scope, scopes...)
+	if config.GuruCache {
+		query.Cache = guruCache
+	}
 
-	var (
-		outputMu sync.Mutex
-		err      error
-	)
+	var outputMu sync.Mutex
 	output := func(fset *token.FileSet, qr guru.QueryResult) {
 		var err error
 		outputMu.Lock()
@@ -190,6 +201,47 @@ func (c *Command) Guru(args []string, eval *funcGuruEval) interface{} {
 
 var errTypeAssertion = errors.New("type assertion error")
 
+// guruScope returns the guru analysis scope of the file package.
+func (c *Command) guruScope(file string) ([]string, error) {
+	// the user defined scope by GoGuruScope takes precedence over the default scope
+	scopes := loadGuruScope(c.guruScopeRoot(filepath.Dir(file)))
+	switch {
+	case len(scopes) > 0:
+		// nothing to do
+	case c.ctx.Build.Tool == "mod":
+		if c.ctx.Build.WorkspaceRoot != "" {
+			for _, mod := range c.ctx.Build.Modules {
+				modPath, err := pathutil.ModulePath(mod)
+				if err != nil {
+					return nil, errors.Wrap(err, "could not get workspace modules")
+				}
+				scopes = append(scopes, pathutil.ToWildcard(modPath))
+			}
+			log.Debug(scopes)
+			break
+		}
+		fallthrough
+	case c.ctx.Build.Tool == "go":
+		pkgID, err := pathutil.PackageID(filepath.Dir(file))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		scopes = []string{pathutil.ToWildcard(pkgID)}
+	case c.ctx.Build.Tool == "gb":
+		var err error
+		scopes, err = pathutil.GbPackages(c.ctx.Build.ProjectRoot)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get gb packages")
+		}
+		for i, pkg := range scopes {
+			scopes[i] = pathutil.ToWildcard(pkg)
+		}
+		log.Debug(scopes)
+	}
+
+	return scopes, nil
+}
+
 func parseResult(mode string, res interface{}, cwd string) ([]*nvim.QuickfixError, error) {
 	if config.DebugEnable {
 		log.Printf("res:\n%+v\n", spew.Sdump(res))
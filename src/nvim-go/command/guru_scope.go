@@ -0,0 +1,165 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+// GoGuruScope
+
+// cmdGuruScopeEval struct type for Eval of GoGuruScope command.
+type cmdGuruScopeEval struct {
+	Cwd string `msgpack:",array"`
+	Dir string
+}
+
+var (
+	guruScopeMu sync.Mutex
+	// guruScopes user defined guru scope of each project root. nil if not loaded yet.
+	guruScopes map[string][]string
+)
+
+// guruScopeFile returns the file path of the persisted guru scopes.
+func guruScopeFile() string {
+	return filepath.Join(config.ConfigHome, "guru", "scope.json")
+}
+
+// loadGuruScope returns the user defined guru scope of the root project.
+func loadGuruScope(root string) []string {
+	guruScopeMu.Lock()
+	defer guruScopeMu.Unlock()
+
+	if guruScopes == nil {
+		guruScopes = make(map[string][]string)
+		if data, err := ioutil.ReadFile(guruScopeFile()); err == nil {
+			json.Unmarshal(data, &guruScopes)
+		}
+	}
+
+	return guruScopes[root]
+}
+
+// saveGuruScope sets the guru scope of the root project and persists the all scopes.
+// The scope of the root is removed if scope is empty.
+func saveGuruScope(root string, scope []string) error {
+	loadGuruScope(root) // make sure loaded
+
+	guruScopeMu.Lock()
+	defer guruScopeMu.Unlock()
+
+	if len(scope) == 0 {
+		delete(guruScopes, root)
+	} else {
+		guruScopes[root] = scope
+	}
+
+	file := guruScopeFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	data, err := json.MarshalIndent(guruScopes, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(ioutil.WriteFile(file, data, 0600))
+}
+
+// guruScopeRoot returns the project root directory of dir that the guru scope stored.
+func (c *Command) guruScopeRoot(dir string) string {
+	switch c.ctx.Build.Tool {
+	case "mod":
+		return c.ctx.Build.Root()
+	case "gb":
+		return c.ctx.Build.ProjectRoot
+	default:
+		return pathutil.FindVCSRoot(dir)
+	}
+}
+
+func (c *Command) cmdGuruScope(args []string, bang bool, eval *cmdGuruScopeEval) {
+	go func() {
+		if err := c.GuruScope(args, bang, eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// GuruScope sets the guru scope of the current project to args, such as
+// "foo.org/foo/..." or "-foo.org/foo/internal/...". Clears the scope if bang
+// is true, and shows the current scope if args is empty.
+func (c *Command) GuruScope(args []string, bang bool, eval *cmdGuruScopeEval) error {
+	defer nvimutil.Profile(time.Now(), "GoGuruScope")
+
+	root := c.guruScopeRoot(eval.Dir)
+	switch {
+	case bang:
+		if err := saveGuruScope(root, nil); err != nil {
+			return err
+		}
+		return nvimutil.Echomsg(c.Nvim, "GoGuruScope: cleared")
+
+	case len(args) == 0:
+		scope := loadGuruScope(root)
+		if len(scope) == 0 {
+			return nvimutil.Echomsg(c.Nvim, "GoGuruScope: not set, uses the default scope")
+		}
+		return nvimutil.Echomsg(c.Nvim, "GoGuruScope:", strings.Join(scope, " "))
+	}
+
+	if err := saveGuruScope(root, args); err != nil {
+		return err
+	}
+	return nvimutil.Echomsg(c.Nvim, "GoGuruScope:", strings.Join(args, " "))
+}
+
+// cmdGuruScopeComplete completes the package patterns of the current project.
+func (c *Command) cmdGuruScopeComplete(a *nvim.CommandCompletionArgs, dir string) ([]string, error) {
+	pkgs, err := c.projectPackages(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return guruScopeCandidates(pkgs, a.ArgLead), nil
+}
+
+// guruScopeCandidates returns the package patterns of pkgs and its wildcards
+// that matched to the lead. The lead may have the "-" prefix that excludes the packages.
+func guruScopeCandidates(pkgs []string, lead string) []string {
+	prefix := ""
+	if strings.HasPrefix(lead, "-") {
+		prefix, lead = "-", lead[1:]
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, pkg := range pkgs {
+		for _, pattern := range []string{pkg, pathutil.ToWildcard(pkg)} {
+			if seen[pattern] || !strings.HasPrefix(pattern, lead) {
+				continue
+			}
+			seen[pattern] = true
+			candidates = append(candidates, prefix+pattern)
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates
+}
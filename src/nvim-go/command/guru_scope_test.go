@@ -0,0 +1,48 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuruScopeCandidates(t *testing.T) {
+	pkgs := []string{"foo.org/foo", "foo.org/foo/bar", "foo.org/baz"}
+
+	type args struct {
+		lead string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "prefix",
+			args: args{lead: "foo.org/foo"},
+			want: []string{"foo.org/foo", "foo.org/foo/...", "foo.org/foo/bar", "foo.org/foo/bar/..."},
+		},
+		{
+			name: "exclude",
+			args: args{lead: "-foo.org/b"},
+			want: []string{"-foo.org/baz", "-foo.org/baz/..."},
+		},
+		{
+			name: "no match",
+			args: args{lead: "bar.org"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := guruScopeCandidates(pkgs, tt.args.lead); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("guruScopeCandidates(%v, %q) = %v, want %v", pkgs, tt.args.lead, got, tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/format"
 	"go/parser"
 	"go/token"
@@ -43,7 +42,7 @@ func (c *Command) Iferr(file string) error {
 	conf := loader.Config{
 		ParserMode:  parser.ParseComments,
 		TypeChecker: types.Config{FakeImportC: true, DisableUnusedImportCheck: true},
-		Build:       &build.Default,
+		Build:       buildTagsContext(),
 		Cwd:         filepath.Dir(file),
 		AllowErrors: true,
 	}
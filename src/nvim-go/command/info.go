@@ -0,0 +1,379 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash/fnv"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/buildutil"
+)
+
+// ----------------------------------------------------------------------------
+// GoInfo
+
+// CmdInfoEval struct type for Eval of GoInfo command.
+type CmdInfoEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	Offset   int
+	BufNr    int
+}
+
+// infoAugroup augroup name of the info window close autocmd.
+const infoAugroup = "nvim-go-info"
+
+const (
+	// infoMaxWidth maximum width of the info floating window.
+	infoMaxWidth = 80
+	// infoMaxHeight maximum height of the info floating window.
+	infoMaxHeight = 20
+)
+
+var (
+	infoMu sync.Mutex
+	// infoWin the opened info floating window.
+	infoWin nvim.Window
+	// infoKey and infoLines the last identifier and its info lines, for skip
+	// the re-query if the cursor stays on the same identifier.
+	infoKey   string
+	infoLines []string
+)
+
+func (c *Command) cmdInfo(eval *CmdInfoEval) {
+	go func() {
+		if err := c.Info(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Info shows the type signature and the doc comment of the identifier under
+// the cursor to the floating window, uses the guru describe query.
+// The describe result is reused if the cursor stays on the same identifier.
+// Returns the guru error, the caller of the CursorHold ignores it.
+func (c *Command) Info(eval *CmdInfoEval) error {
+	defer nvimutil.Profile(time.Now(), "GoInfo")
+
+	b := nvim.Buffer(eval.BufNr)
+	buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	src := bytes.Join(buf, []byte{'\n'})
+
+	start, ok := identAt(eval.File, src, eval.Offset)
+	if !ok {
+		// not an error if the cursor is not on the identifier
+		return c.InfoClose()
+	}
+	key := infoCacheKey(eval.File, src, start)
+
+	infoMu.Lock()
+	lines := infoLines
+	if key != infoKey {
+		lines = nil
+	}
+	infoMu.Unlock()
+
+	if lines == nil {
+		guruContext := buildTagsContext()
+		if eval.Modified != 0 {
+			overlay := map[string][]byte{eval.File: src}
+			guruContext = buildutil.OverlayContext(guruContext, overlay)
+		}
+
+		var (
+			describe *serial.Describe
+			summary  string
+		)
+		query := guru.Query{
+			Pos:   fmt.Sprintf("%s:#%d", eval.File, start),
+			Build: guruContext,
+			Output: func(fset *token.FileSet, qr guru.QueryResult) {
+				describe, _ = qr.Result(fset).(*serial.Describe)
+				// the first line of the plain result describes the object with its type
+				qr.PrintPlain(func(_ interface{}, format string, args ...interface{}) {
+					if summary == "" {
+						summary = fmt.Sprintf(format, args...)
+					}
+				})
+			},
+		}
+		if err := guru.Run("describe", &query); err != nil {
+			c.InfoClose()
+			return errors.WithStack(err)
+		}
+		if describe == nil {
+			return c.InfoClose()
+		}
+
+		lines = describeLines(describe, summary, func(filename string) ([]byte, error) {
+			if filename == eval.File {
+				return src, nil
+			}
+			return ioutil.ReadFile(filename)
+		})
+		if len(lines) == 0 {
+			return c.InfoClose()
+		}
+
+		infoMu.Lock()
+		infoKey, infoLines = key, lines
+		infoMu.Unlock()
+	}
+
+	return c.openInfoWindow(b, lines)
+}
+
+// identAt returns the offset of the start of the identifier at offset in src.
+func identAt(filename string, src []byte, offset int) (int, bool) {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, filename, src, parser.AllErrors)
+	if f == nil || offset < 0 || offset > len(src) {
+		return 0, false
+	}
+	tf := fset.File(f.Pos())
+	pos := tf.Pos(offset)
+
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	if len(path) == 0 {
+		return 0, false
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return 0, false
+	}
+
+	return tf.Offset(id.Pos()), true
+}
+
+// infoCacheKey returns the cache key of the identifier at start in the src of filename.
+func infoCacheKey(filename string, src []byte, start int) string {
+	h := fnv.New64a()
+	h.Write(src)
+	return fmt.Sprintf("%s:#%d:%x", filename, start, h.Sum64())
+}
+
+// describeLines returns the info window lines of the describe result d and its
+// plain text summary, the type signature and the doc comment of the declaration.
+// readFile reads the declaration file for parse the doc comment.
+func describeLines(d *serial.Describe, summary string, readFile func(string) ([]byte, error)) []string {
+	var (
+		sig    string
+		declAt string
+	)
+	switch d.Detail {
+	case "package":
+		sig = "package " + d.Package.Path
+	case "type":
+		sig = trimDescribePrefix(summary)
+		if d.Type.NameDef != "" {
+			sig = fmt.Sprintf("type %s %s", d.Type.Type, d.Type.NameDef)
+		}
+		declAt = d.Type.NamePos
+	case "value":
+		sig = trimDescribePrefix(summary)
+		declAt = d.Value.ObjPos
+	default:
+		sig = summary
+	}
+	if sig == "" {
+		return nil
+	}
+
+	lines := strings.Split(sig, "\n")
+	if declAt == "" {
+		return lines
+	}
+	filename, line, col, ok := splitDescribePos(declAt)
+	if !ok {
+		return lines
+	}
+	src, err := readFile(filename)
+	if err != nil {
+		return lines
+	}
+	if doc := docComment(filename, src, line, col); doc != "" {
+		lines = append(append(lines, ""), strings.Split(doc, "\n")...)
+	}
+
+	return lines
+}
+
+// trimDescribePrefix trims the "reference to" or "definition of" prefix of the describe description.
+func trimDescribePrefix(desc string) string {
+	for _, prefix := range []string{"reference to ", "definition of "} {
+		if strings.HasPrefix(desc, prefix) {
+			return strings.TrimPrefix(desc, prefix)
+		}
+	}
+	return desc
+}
+
+// splitDescribePos splits the "file:line:col" position of the describe result.
+func splitDescribePos(pos string) (string, int, int, bool) {
+	f := strings.Split(pos, ":")
+	if len(f) < 3 {
+		return "", 0, 0, false
+	}
+	line, err := strconv.Atoi(f[len(f)-2])
+	if err != nil {
+		return "", 0, 0, false
+	}
+	col, err := strconv.Atoi(f[len(f)-1])
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	return strings.Join(f[:len(f)-2], ":"), line, col, true
+}
+
+// docComment returns the doc comment text of the declaration at line and col in the src of filename.
+func docComment(filename string, src []byte, line, col int) string {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.AllErrors)
+	if f == nil {
+		return ""
+	}
+	tf := fset.File(f.Pos())
+	if line < 1 || line > tf.LineCount() {
+		return ""
+	}
+	pos := tf.LineStart(line) + token.Pos(col-1)
+
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return commentText(n.Doc)
+		case *ast.Field:
+			return commentText(n.Doc)
+		case *ast.TypeSpec:
+			if n.Doc != nil {
+				return commentText(n.Doc)
+			}
+		case *ast.ValueSpec:
+			if n.Doc != nil {
+				return commentText(n.Doc)
+			}
+		case *ast.GenDecl:
+			// the doc comment of the grouped declaration is not for each specs
+			if n.Lparen.IsValid() {
+				return ""
+			}
+			return commentText(n.Doc)
+		case *ast.File:
+			return commentText(n.Doc)
+		}
+	}
+
+	return ""
+}
+
+// commentText returns the trimmed text of the doc comment, or empty if doc is nil.
+func commentText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// openInfoWindow opens the info floating window of lines under the cursor,
+// and closes it when the cursor is moved.
+func (c *Command) openInfoWindow(b nvim.Buffer, lines []string) error {
+	if err := c.InfoClose(); err != nil {
+		return err
+	}
+
+	width, height := 0, len(lines)
+	replacement := make([][]byte, len(lines))
+	for i, line := range lines {
+		replacement[i] = []byte(line)
+		if w := utf8.RuneCountInString(line); w > width {
+			width = w
+		}
+	}
+	if width > infoMaxWidth {
+		width = infoMaxWidth
+	}
+	if height > infoMaxHeight {
+		height = infoMaxHeight
+	}
+
+	var buf nvim.Buffer
+	if err := c.Nvim.Call("nvim_create_buf", &buf, false, true); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := c.Nvim.SetBufferLines(buf, 0, -1, true, replacement); err != nil {
+		return errors.WithStack(err)
+	}
+
+	opts := map[string]interface{}{
+		"relative":  "cursor",
+		"row":       1,
+		"col":       0,
+		"width":     width,
+		"height":    height,
+		"focusable": false,
+		"style":     "minimal",
+	}
+	var win nvim.Window
+	if err := c.Nvim.Call("nvim_open_win", &win, buf, false, opts); err != nil {
+		return errors.WithStack(err)
+	}
+
+	infoMu.Lock()
+	infoWin = win
+	infoMu.Unlock()
+
+	batch := c.Nvim.NewBatch()
+	batch.SetBufferOption(buf, "filetype", "go")
+	batch.SetBufferOption(buf, "bufhidden", "wipe")
+	batch.Command("augroup " + infoAugroup)
+	batch.Command("augroup END")
+	batch.Command(fmt.Sprintf("autocmd! %s * <buffer=%d>", infoAugroup, b))
+	// defer the close autocmd to the next event loop, otherwise the pending
+	// CursorMoved of the window opening closes the window immediately
+	batch.Command(fmt.Sprintf("call timer_start(0, {-> execute('autocmd %s CursorMoved,CursorMovedI,InsertEnter,BufLeave <buffer=%d> call rpcnotify(%d, ''GoInfoClose'')')})", infoAugroup, b, config.ChannelID))
+
+	return errors.WithStack(batch.Execute())
+}
+
+// InfoClose closes the info floating window if opened.
+func (c *Command) InfoClose() error {
+	infoMu.Lock()
+	win := infoWin
+	infoWin = 0
+	infoMu.Unlock()
+
+	if win == 0 {
+		return nil
+	}
+	if valid, err := c.Nvim.IsWindowValid(win); err != nil || !valid {
+		return nil
+	}
+
+	return errors.WithStack(c.Nvim.Call("nvim_win_close", nil, win, true))
+}
@@ -0,0 +1,190 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+var infoTestSrc = []byte(`package foo
+
+// Foo is the foo type.
+type Foo struct {
+	// Bar is the bar field.
+	Bar int
+}
+
+// New returns the new Foo.
+func New() *Foo { return &Foo{} }
+
+const (
+	// Baz is the baz constant.
+	Baz = 1
+	Qux = 2
+)
+`)
+
+func TestIdentAt(t *testing.T) {
+	type args struct {
+		src    []byte
+		offset int
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   int
+		wantOk bool
+	}{
+		{
+			name:   "start of identifier",
+			args:   args{src: infoTestSrc, offset: strings.Index(string(infoTestSrc), "Foo struct")},
+			want:   strings.Index(string(infoTestSrc), "Foo struct"),
+			wantOk: true,
+		},
+		{
+			name:   "middle of identifier",
+			args:   args{src: infoTestSrc, offset: strings.Index(string(infoTestSrc), "New()") + 2},
+			want:   strings.Index(string(infoTestSrc), "New()"),
+			wantOk: true,
+		},
+		{
+			name:   "not identifier",
+			args:   args{src: infoTestSrc, offset: strings.Index(string(infoTestSrc), "{ return")},
+			wantOk: false,
+		},
+		{
+			name:   "out of range",
+			args:   args{src: infoTestSrc, offset: len(infoTestSrc) + 1},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := identAt("foo.go", tt.args.src, tt.args.offset)
+			if ok != tt.wantOk {
+				t.Errorf("identAt(%v) ok = %v, want %v", tt.args.offset, ok, tt.wantOk)
+				return
+			}
+			if ok && got != tt.want {
+				t.Errorf("identAt(%v) = %v, want %v", tt.args.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocComment(t *testing.T) {
+	type args struct {
+		line int
+		col  int
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "type",
+			args: args{line: 4, col: 6},
+			want: "Foo is the foo type.",
+		},
+		{
+			name: "field",
+			args: args{line: 6, col: 2},
+			want: "Bar is the bar field.",
+		},
+		{
+			name: "func",
+			args: args{line: 10, col: 6},
+			want: "New returns the new Foo.",
+		},
+		{
+			name: "grouped const",
+			args: args{line: 14, col: 2},
+			want: "Baz is the baz constant.",
+		},
+		{
+			name: "grouped const without doc",
+			args: args{line: 15, col: 2},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := docComment("foo.go", infoTestSrc, tt.args.line, tt.args.col); got != tt.want {
+				t.Errorf("docComment(%v, %v) = %q, want %q", tt.args.line, tt.args.col, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeLines(t *testing.T) {
+	readFile := func(string) ([]byte, error) { return infoTestSrc, nil }
+
+	type args struct {
+		d       *serial.Describe
+		summary string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "value with doc",
+			args: args{
+				d: &serial.Describe{
+					Detail: "value",
+					Value:  &serial.DescribeValue{Type: "func() *foo.Foo", ObjPos: "/src/foo/foo.go:10:6"},
+				},
+				summary: "reference to func foo.New() *foo.Foo",
+			},
+			want: []string{"func foo.New() *foo.Foo", "", "New returns the new Foo."},
+		},
+		{
+			name: "named type",
+			args: args{
+				d: &serial.Describe{
+					Detail: "type",
+					Type:   &serial.DescribeType{Type: "foo.Foo", NamePos: "/src/foo/foo.go:4:6", NameDef: "struct{Bar int}"},
+				},
+				summary: "definition of type foo.Foo (size 8, align 8)",
+			},
+			want: []string{"type foo.Foo struct{Bar int}", "", "Foo is the foo type."},
+		},
+		{
+			name: "package",
+			args: args{
+				d:       &serial.Describe{Detail: "package", Package: &serial.DescribePackage{Path: "foo"}},
+				summary: "definition of package \"foo\"",
+			},
+			want: []string{"package foo"},
+		},
+		{
+			name: "statement",
+			args: args{
+				d:       &serial.Describe{Desc: "for loop"},
+				summary: "for loop",
+			},
+			want: []string{"for loop"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := describeLines(tt.args.d, tt.args.summary, readFile); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("describeLines(%v, %v) = %v, want %v", tt.args.d, tt.args.summary, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/build"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// projectPackages returns the import paths of the all packages in the
+// current project of dir. Returns the main packages only if mainOnly is true.
+func (c *Command) projectPackages(dir string, mainOnly bool) ([]string, error) {
+	var pkgs []string
+	match := func(p *build.Package) bool { return !mainOnly || p.Name == "main" }
+
+	switch c.ctx.Build.Tool {
+	case "mod":
+		for _, mod := range c.ctx.Build.ModuleRoots() {
+			found, err := pathutil.FindAllPackage(mod, build.Default, nil, pathutil.ModeExcludeVendor|pathutil.ModeExcludeModule)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			for _, p := range found {
+				if !match(p) {
+					continue
+				}
+				pkgID, err := pathutil.ModuleImportPath(mod, p.Dir)
+				if err != nil {
+					continue
+				}
+				pkgs = append(pkgs, pkgID)
+			}
+		}
+	case "gb":
+		src := filepath.Join(c.ctx.Build.ProjectRoot, "src")
+		found, err := pathutil.FindAllPackage(src, build.Default, nil, pathutil.ModeExcludeVendor)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, p := range found {
+			if !match(p) {
+				continue
+			}
+			if rel, err := filepath.Rel(src, p.Dir); err == nil {
+				pkgs = append(pkgs, filepath.ToSlash(rel))
+			}
+		}
+	default:
+		found, err := pathutil.FindAllPackage(pathutil.FindVCSRoot(dir), build.Default, nil, pathutil.ModeExcludeVendor)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, p := range found {
+			if !match(p) {
+				continue
+			}
+			pkgs = append(pkgs, pathutil.TrimGoPath(p.Dir))
+		}
+	}
+	sort.Strings(pkgs)
+
+	return pkgs, nil
+}
+
+// cmdInstallComplete completes the import paths of the current project packages.
+func (c *Command) cmdInstallComplete(a *nvim.CommandCompletionArgs, dir string) ([]string, error) {
+	pkgs, err := c.projectPackages(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg, a.ArgLead) {
+			candidates = append(candidates, pkg)
+		}
+	}
+
+	return candidates, nil
+}
+
+// ----------------------------------------------------------------------------
+// GoInstall
+
+func (c *Command) cmdInstall(args []string, bang bool, eval *CmdBuildEval) {
+	go func() {
+		c.errs.Delete("Install")
+
+		err := c.Install(args, bang, eval)
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Install", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// Install installs the args packages, or the current buffers package if args
+// is empty, use compile tool that determined from the package directory structure.
+// If bang is true, installs the all commands (main packages) in the project.
+func (c *Command) Install(args []string, bang bool, eval *CmdBuildEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoInstall")
+
+	dir := filepath.Dir(eval.File)
+
+	pkgs := args
+	switch {
+	case bang:
+		mains, err := c.projectPackages(dir, true)
+		if err != nil {
+			return err
+		}
+		if len(mains) == 0 {
+			return nvimutil.Echomsg(c.Nvim, "GoInstall: no commands in the project")
+		}
+		pkgs = mains
+	case len(pkgs) == 0 && c.ctx.Build.Tool == "gb":
+		// gb builds the all project packages without the package args
+		rel, err := filepath.Rel(filepath.Join(c.ctx.Build.ProjectRoot, "src"), dir)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		pkgs = []string{filepath.ToSlash(rel)}
+	}
+
+	cmd, err := c.installCmd(dir, pkgs)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if installErr := cmd.Run(); installErr != nil {
+		if _, ok := installErr.(*exec.ExitError); !ok {
+			return errors.WithStack(installErr)
+		}
+		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(errlist) == 0 {
+			return errors.Errorf("GoInstall: %s", strings.TrimSpace(stderr.String()))
+		}
+		return errlist
+	}
+
+	installed := "."
+	if len(pkgs) > 0 {
+		installed = strings.Join(pkgs, " ")
+	}
+	return nvimutil.EchoSuccess(c.Nvim, "GoInstall", fmt.Sprintf("installed: %s", installed))
+}
+
+// installCmd returns the *exec.Cmd that installs the pkgs corresponding to the compile tool.
+// gb does not have the install command, but "gb build" installs the commands to the $PROJECT/bin.
+func (c *Command) installCmd(dir string, pkgs []string) (*exec.Cmd, error) {
+	cmd, err := c.toolCmd("install", true, dir)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = append(cmd.Args, pkgs...)
+
+	return cmd, nil
+}
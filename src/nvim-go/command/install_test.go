@@ -0,0 +1,54 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nvim-go/ctx"
+)
+
+func TestCommand_projectPackages(t *testing.T) {
+	type args struct {
+		dir      string
+		mainOnly bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "gb all packages",
+			args:    args{dir: gsftp, mainOnly: false},
+			want:    []string{"cmd/gsftp"},
+			wantErr: false,
+		},
+		{
+			name:    "gb main packages",
+			args:    args{dir: gsftp, mainOnly: true},
+			want:    []string{"cmd/gsftp"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewCommand(nil, &ctx.Context{Build: ctx.Build{Tool: "gb", ProjectRoot: filepath.Join(testGbPath, "gsftp")}})
+			got, err := c.projectPackages(tt.args.dir, tt.args.mainOnly)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Command.projectPackages(%v, %v) error = %v, wantErr %v", tt.args.dir, tt.args.mainOnly, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Command.projectPackages(%v, %v) = %v, want %v", tt.args.dir, tt.args.mainOnly, got, tt.want)
+			}
+		})
+	}
+}
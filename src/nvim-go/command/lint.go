@@ -16,7 +16,6 @@ import (
 	"time"
 
 	"nvim-go/config"
-	"nvim-go/log"
 	"nvim-go/nvimutil"
 	"nvim-go/pathutil"
 
@@ -71,6 +70,22 @@ func (c *Command) Lint(args []string, file string) ([]*nvim.QuickfixError, error
 				rootDir = root
 			case "gb":
 				rootDir = filepath.Base(c.ctx.Build.ProjectRoot)
+			case "mod":
+				// module packages are not under the GOPATH, so walk each module root directory
+				for _, mod := range c.ctx.Build.ModuleRoots() {
+					pkgs, err := pathutil.FindAllPackage(mod, build.Default, nil, pathutil.ModeExcludeVendor|pathutil.ModeExcludeModule)
+					if err != nil {
+						return nil, errors.WithStack(err)
+					}
+					for _, pkg := range pkgs {
+						errors, err := c.lintDir(pkg.Dir)
+						if err != nil {
+							return nil, err
+						}
+						errlist = append(errlist, errors...)
+					}
+				}
+				return errlist, nil
 			}
 			for _, pkgname := range importPaths([]string{rootDir + "/..."}) {
 				errors, err := c.lintPackage(pkgname)
@@ -174,12 +189,12 @@ func contain(s string, ignore []string) bool {
 }
 
 func (c *Command) lintDir(dirname string) ([]*nvim.QuickfixError, error) {
-	pkg, err := build.ImportDir(dirname, 0)
+	pkg, err := buildTagsContext().ImportDir(dirname, 0)
 	return c.lintImportedPackage(pkg, err)
 }
 
 func (c *Command) lintPackage(pkgname string) ([]*nvim.QuickfixError, error) {
-	pkg, err := build.Import(pkgname, ".", 0)
+	pkg, err := buildTagsContext().Import(pkgname, ".", 0)
 	return c.lintImportedPackage(pkg, err)
 }
 
@@ -45,6 +45,10 @@ func (c *Command) Metalinter(cwd string) error {
 		args = append(args, cwd+"/...")
 	case "gb":
 		args = append(args, c.ctx.Build.ProjectRoot+"/...")
+	case "mod":
+		for _, mod := range c.ctx.Build.ModuleRoots() {
+			args = append(args, mod+"/...")
+		}
 	}
 	args = append(args, []string{"--json", "--disable-all", "--deadline", config.MetalinterDeadline}...)
 
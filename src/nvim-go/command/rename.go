@@ -6,7 +6,6 @@ package command
 
 import (
 	"fmt"
-	"go/build"
 	"io/ioutil"
 	"os"
 	"time"
@@ -96,7 +95,7 @@ func (c *Command) Rename(args []string, bang bool, eval *cmdRenameEval) interfac
 	}()
 
 	// TODO(zchee): reached race limit, dying when race build
-	if err := rename.Main(&build.Default, pos, "", renameTo); err != nil {
+	if err := rename.Main(buildTagsContext(), pos, "", renameTo); err != nil {
 		write.Close()
 		renameErr, err := ioutil.ReadAll(read)
 		if err != nil {
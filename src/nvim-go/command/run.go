@@ -46,7 +46,8 @@ func (c *Command) cmdRunLast(file string) {
 func (c *Command) Run(args []string, file string) error {
 	defer nvimutil.Profile(time.Now(), "GoRun")
 
-	cmd := []string{"go", "run", file}
+	cmd := append([]string{"go", "run"}, buildTagsFlag()...)
+	cmd = append(cmd, file)
 	if len(args) != 0 {
 		runLastArgs = args
 		cmd = append(cmd, args...)
@@ -0,0 +1,183 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/internal/guru"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/cmd/guru/serial"
+	"golang.org/x/tools/go/buildutil"
+)
+
+// ----------------------------------------------------------------------------
+// GoSameIds
+
+// CmdSameIdsEval struct type for Eval of GoSameIds command.
+type CmdSameIdsEval struct {
+	Cwd      string `msgpack:",array"`
+	File     string
+	Modified int
+	Offset   int
+	BufNr    int
+}
+
+// sameIdsAugroup augroup name of the same identifiers clear autocmd.
+const sameIdsAugroup = "nvim-go-sameids"
+
+var (
+	sameIdsMu sync.Mutex
+	// sameIdsBufs highlighted buffers of the same identifiers.
+	sameIdsBufs = make(map[nvim.Buffer]bool)
+)
+
+func (c *Command) cmdSameIds(eval *CmdSameIdsEval) {
+	go func() {
+		if err := c.SameIds(eval); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// SameIds highlights the all identifiers in the current buffer that same
+// object of the identifier under the cursor, uses the guru what query.
+func (c *Command) SameIds(eval *CmdSameIdsEval) error {
+	defer nvimutil.Profile(time.Now(), "GoSameIds")
+
+	b := nvim.Buffer(eval.BufNr)
+
+	guruContext := buildTagsContext()
+	if eval.Modified != 0 {
+		buf, err := c.Nvim.BufferLines(b, 0, -1, true)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		overlay := map[string][]byte{eval.File: bytes.Join(buf, []byte{'\n'})}
+		guruContext = buildutil.OverlayContext(guruContext, overlay)
+	}
+
+	var what *serial.What
+	query := guru.Query{
+		Pos:   fmt.Sprintf("%s:#%d", eval.File, eval.Offset),
+		Build: guruContext,
+		Output: func(fset *token.FileSet, qr guru.QueryResult) {
+			what, _ = qr.Result(fset).(*serial.What)
+		},
+	}
+	if err := guru.Run("what", &query); err != nil {
+		// not an error if the cursor is not on the Go syntax such as the blank line
+		return c.SameIdsClear()
+	}
+	if what == nil || what.Object == "" {
+		return c.SameIdsClear()
+	}
+
+	return c.highlightSameIds(b, eval.File, what)
+}
+
+// sameIdsRanges returns the highlight ranges of the same identifiers in file.
+// The line and column are started by 0 for nvim_buf_add_highlight.
+func sameIdsRanges(file string, what *serial.What) [][3]int {
+	var ranges [][3]int
+	for _, pos := range what.SameIDs {
+		// pos is "file:line:col"
+		f := strings.Split(pos, ":")
+		if len(f) < 3 || strings.Join(f[:len(f)-2], ":") != file {
+			continue
+		}
+		line, err := strconv.Atoi(f[len(f)-2])
+		if err != nil {
+			continue
+		}
+		col, err := strconv.Atoi(f[len(f)-1])
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, [3]int{line - 1, col - 1, col - 1 + len(what.Object)})
+	}
+
+	return ranges
+}
+
+// highlightSameIds highlights the same identifiers of the what result in the b buffer.
+func (c *Command) highlightSameIds(b nvim.Buffer, file string, what *serial.What) error {
+	ns, err := nvimutil.CreateNamespace(c.Nvim, sameIdsAugroup)
+	if err != nil {
+		return err
+	}
+
+	sameIdsMu.Lock()
+	defer sameIdsMu.Unlock()
+
+	var res int // for ignore the msgpack decode errror. not used
+	batch := c.Nvim.NewBatch()
+	batch.Call("nvim_buf_clear_namespace", nil, int(b), ns, 0, -1)
+	for _, r := range sameIdsRanges(file, what) {
+		batch.AddBufferHighlight(b, ns, "GoSameId", r[0], r[1], r[2], &res)
+	}
+
+	// clears the highlights when the cursor is moved
+	if config.GuruSameIdsAuto {
+		batch.Command("augroup " + sameIdsAugroup)
+		batch.Command("augroup END")
+		batch.Command(fmt.Sprintf("autocmd! %s * <buffer=%d>", sameIdsAugroup, b))
+		batch.Command(fmt.Sprintf("autocmd %s CursorMoved,CursorMovedI <buffer=%d> call rpcnotify(%d, 'GoSameIdsClear')", sameIdsAugroup, b, config.ChannelID))
+	}
+	if err := batch.Execute(); err != nil {
+		return errors.WithStack(err)
+	}
+	sameIdsBufs[b] = true
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// GoSameIdsClear
+
+func (c *Command) cmdSameIdsClear() {
+	go func() {
+		if err := c.SameIdsClear(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// SameIdsClear clears the same identifiers highlights of the all buffers.
+func (c *Command) SameIdsClear() error {
+	sameIdsMu.Lock()
+	defer sameIdsMu.Unlock()
+
+	if len(sameIdsBufs) == 0 {
+		return nil
+	}
+
+	ns, err := nvimutil.CreateNamespace(c.Nvim, sameIdsAugroup)
+	if err != nil {
+		return err
+	}
+
+	batch := c.Nvim.NewBatch()
+	for b := range sameIdsBufs {
+		if !nvimutil.IsBufferValid(c.Nvim, b) {
+			continue
+		}
+		batch.Call("nvim_buf_clear_namespace", nil, int(b), ns, 0, -1)
+		batch.Command(fmt.Sprintf("silent! autocmd! %s * <buffer=%d>", sameIdsAugroup, b))
+	}
+	sameIdsBufs = make(map[nvim.Buffer]bool)
+
+	return errors.WithStack(batch.Execute())
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/cmd/guru/serial"
+)
+
+func TestSameIdsRanges(t *testing.T) {
+	type args struct {
+		file string
+		what *serial.What
+	}
+	tests := []struct {
+		name string
+		args args
+		want [][3]int
+	}{
+		{
+			name: "same file only",
+			args: args{
+				file: "/src/foo/foo.go",
+				what: &serial.What{
+					Object: "bar",
+					SameIDs: []string{
+						"/src/foo/foo.go:3:6",
+						"/src/foo/foo.go:10:2",
+						"/src/foo/baz.go:1:1",
+					},
+				},
+			},
+			want: [][3]int{{2, 5, 8}, {9, 1, 4}},
+		},
+		{
+			name: "no identifiers",
+			args: args{
+				file: "/src/foo/foo.go",
+				what: &serial.What{Object: "bar"},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := sameIdsRanges(tt.args.file, tt.args.what); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sameIdsRanges(%v, %v) = %v, want %v", tt.args.file, tt.args.what, got, tt.want)
+			}
+		})
+	}
+}
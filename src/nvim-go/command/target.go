@@ -0,0 +1,232 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// parseTarget parses the "goos/goarch" or "goos goarch" target platform args.
+// goarch is empty if args has the goos only.
+func parseTarget(args []string) (goos, goarch string, err error) {
+	target := strings.Join(args, "/")
+	f := strings.Split(target, "/")
+	switch {
+	case len(f) > 2 || f[0] == "":
+		return "", "", errors.Errorf("invalid target: %s", target)
+	case len(f) == 2:
+		return f[0], f[1], nil
+	default:
+		return f[0], "", nil
+	}
+}
+
+var (
+	distListOnce sync.Once
+	// distList cache of the supported target platforms of the go tool.
+	distList []string
+)
+
+// targetCandidates returns the supported "goos/goarch" target platforms
+// of the go tool that matched to the lead.
+func targetCandidates(lead, dir string) []string {
+	distListOnce.Do(func() {
+		cmd := exec.Command("go", "tool", "dist", "list")
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return
+		}
+		distList = strings.Fields(string(out))
+		sort.Strings(distList)
+	})
+
+	var candidates []string
+	for _, target := range distList {
+		if strings.HasPrefix(target, lead) {
+			candidates = append(candidates, target)
+		}
+	}
+
+	return candidates
+}
+
+// cmdTargetComplete completes the supported target platforms.
+func (c *Command) cmdTargetComplete(a *nvim.CommandCompletionArgs, dir string) ([]string, error) {
+	return targetCandidates(a.ArgLead, dir), nil
+}
+
+// ----------------------------------------------------------------------------
+// GoTarget
+
+func (c *Command) cmdTarget(args []string, bang bool) {
+	go func() {
+		if err := c.Target(args, bang); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// Target sets the GOOS and GOARCH target platform to args such as
+// "windows/amd64", which affects the files that guru and lint see and the
+// environment of Gobuild, Gotest and Govet. Resets to the host platform if
+// bang is true, and shows the current target if args is empty.
+func (c *Command) Target(args []string, bang bool) error {
+	defer nvimutil.Profile(time.Now(), "GoTarget")
+
+	switch {
+	case bang:
+		c.ctx.SetTarget("", "")
+	case len(args) > 0:
+		goos, goarch, err := parseTarget(args)
+		if err != nil {
+			return err
+		}
+		c.ctx.SetTarget(goos, goarch)
+	}
+
+	goos, goarch := c.ctx.Target()
+	return nvimutil.Echomsg(c.Nvim, "GoTarget:", goos+"/"+goarch)
+}
+
+// ----------------------------------------------------------------------------
+// GoBuildMatrix
+
+func (c *Command) cmdBuildMatrix(args []string, eval *CmdBuildEval) {
+	go func() {
+		c.errs.Delete("Build")
+
+		err := c.BuildMatrix(args, eval)
+		switch e := err.(type) {
+		case error:
+			nvimutil.ErrorWrap(c.Nvim, e)
+		case []*nvim.QuickfixError:
+			c.errs.Store("Build", e)
+			errlist := make(map[string][]*nvim.QuickfixError)
+			c.errs.Range(func(ki, vi interface{}) bool {
+				k, v := ki.(string), vi.([]*nvim.QuickfixError)
+				errlist[k] = append(errlist[k], v...)
+				return true
+			})
+			nvimutil.ErrorList(c.Nvim, errlist, true)
+		}
+	}()
+}
+
+// BuildMatrix cross-compiles the current buffers package for each target
+// platforms of args or config.BuildTargets, and reports the errors of each
+// target with the "[goos/goarch]" prefix.
+func (c *Command) BuildMatrix(args []string, eval *CmdBuildEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoBuildMatrix")
+
+	targets := args
+	if len(targets) == 0 {
+		targets = config.BuildTargets
+	}
+	if len(targets) == 0 {
+		return errors.New("GoBuildMatrix: no targets. Set the g:go#build#targets or pass the targets such as linux/amd64")
+	}
+
+	// validate the all targets before starting any build
+	platforms, err := parseMatrixTargets(targets)
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		errlist []*nvim.QuickfixError
+		err     error
+	}
+	results := make([]result, len(platforms))
+
+	var wg sync.WaitGroup
+	for i, p := range platforms {
+		wg.Add(1)
+		go func(i int, goos, goarch string) {
+			defer wg.Done()
+			results[i].errlist, results[i].err = c.buildTarget(goos, goarch, eval)
+		}(i, p[0], p[1])
+	}
+	wg.Wait()
+
+	var errlist []*nvim.QuickfixError
+	for i, res := range results {
+		if res.err != nil {
+			return res.err
+		}
+		for _, e := range res.errlist {
+			e.Text = fmt.Sprintf("[%s] %s", targets[i], e.Text)
+			errlist = append(errlist, e)
+		}
+	}
+	if len(errlist) > 0 {
+		return errlist
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoBuildMatrix", fmt.Sprintf("targets: %s", strings.Join(targets, " ")))
+}
+
+// parseMatrixTargets parses the "goos/goarch" targets of GoBuildMatrix, and
+// returns the pair of GOOS and GOARCH of each target.
+// The GOARCH is required unlike the GoTarget.
+func parseMatrixTargets(targets []string) ([][2]string, error) {
+	platforms := make([][2]string, 0, len(targets))
+	for _, target := range targets {
+		goos, goarch, err := parseTarget([]string{target})
+		if err != nil {
+			return nil, err
+		}
+		if goarch == "" {
+			return nil, errors.Errorf("GoBuildMatrix: missing GOARCH of the target: %s", target)
+		}
+		platforms = append(platforms, [2]string{goos, goarch})
+	}
+
+	return platforms, nil
+}
+
+// buildTarget builds the current buffers package for the goos and goarch
+// target platform without the output binary, and returns the build errors.
+func (c *Command) buildTarget(goos, goarch string, eval *CmdBuildEval) ([]*nvim.QuickfixError, error) {
+	cmd, err := c.compileCmd(false, filepath.Dir(eval.File))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if buildErr := cmd.Run(); buildErr != nil {
+		if _, ok := buildErr.(*exec.ExitError); !ok {
+			return nil, errors.WithStack(buildErr)
+		}
+		errlist, err := nvimutil.ParseError(stderr.Bytes(), eval.Cwd, &c.ctx.Build, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(errlist) == 0 {
+			// such as the unsupported target platform
+			return nil, errors.Errorf("GoBuildMatrix: %s/%s: %s", goos, goarch, strings.TrimSpace(stderr.String()))
+		}
+		return errlist, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantGOOS   string
+		wantGOARCH string
+		wantErr    bool
+	}{
+		{
+			name:       "slash separated",
+			args:       args{args: []string{"windows/amd64"}},
+			wantGOOS:   "windows",
+			wantGOARCH: "amd64",
+			wantErr:    false,
+		},
+		{
+			name:       "space separated",
+			args:       args{args: []string{"linux", "arm64"}},
+			wantGOOS:   "linux",
+			wantGOARCH: "arm64",
+			wantErr:    false,
+		},
+		{
+			name:       "goos only",
+			args:       args{args: []string{"darwin"}},
+			wantGOOS:   "darwin",
+			wantGOARCH: "",
+			wantErr:    false,
+		},
+		{
+			name:    "too many elements",
+			args:    args{args: []string{"linux/amd64/v2"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty goos",
+			args:    args{args: []string{"/amd64"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			goos, goarch, err := parseTarget(tt.args.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTarget(%v) error = %v, wantErr %v", tt.args.args, err, tt.wantErr)
+				return
+			}
+			if goos != tt.wantGOOS || goarch != tt.wantGOARCH {
+				t.Errorf("parseTarget(%v) = %v, %v, want %v, %v", tt.args.args, goos, goarch, tt.wantGOOS, tt.wantGOARCH)
+			}
+		})
+	}
+}
+
+func TestParseMatrixTargets(t *testing.T) {
+	type args struct {
+		targets []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    [][2]string
+		wantErr bool
+	}{
+		{
+			name: "valid targets",
+			args: args{targets: []string{"linux/amd64", "windows/386"}},
+			want: [][2]string{{"linux", "amd64"}, {"windows", "386"}},
+		},
+		{
+			name:    "missing goarch",
+			args:    args{targets: []string{"linux/amd64", "darwin"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid target after the valid one",
+			args:    args{targets: []string{"linux/amd64", "linux/amd64/v2"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseMatrixTargets(tt.args.targets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseMatrixTargets(%v) error = %v, wantErr %v", tt.args.targets, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMatrixTargets(%v) = %v, want %v", tt.args.targets, got, tt.want)
+			}
+		})
+	}
+}
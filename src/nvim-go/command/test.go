@@ -13,8 +13,11 @@ import (
 	"log"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"nvim-go/config"
 	"nvim-go/nvimutil"
@@ -29,7 +32,29 @@ import (
 // GoTest
 
 func (c *Command) cmdTest(args []string, dir string) {
-	go c.Test(args, dir)
+	go func() {
+		c.errs.Delete("Test")
+
+		err := c.Test(args, dir)
+		c.handleTestResult(err)
+	}()
+}
+
+// handleTestResult handles the result of the test commands.
+func (c *Command) handleTestResult(result interface{}) {
+	switch e := result.(type) {
+	case error:
+		nvimutil.ErrorWrap(c.Nvim, e)
+	case []*nvim.QuickfixError:
+		c.errs.Store("Test", e)
+		errlist := make(map[string][]*nvim.QuickfixError)
+		c.errs.Range(func(ki, vi interface{}) bool {
+			k, v := ki.(string), vi.([]*nvim.QuickfixError)
+			errlist[k] = append(errlist[k], v...)
+			return true
+		})
+		nvimutil.ErrorList(c.Nvim, errlist, true)
+	}
 }
 
 // testTerm cache nvimutil.Terminal use global variable.
@@ -37,10 +62,13 @@ var testTerm *nvimutil.Terminal
 
 // Test run the package test command use compile tool that determined from
 // the directory structure.
-func (c *Command) Test(args []string, dir string) error {
+// Returns the errorlist of failed tests if config.TestJSON is enabled.
+func (c *Command) Test(args []string, dir string) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoTest")
 
-	cmd := []string{c.ctx.Build.Tool, "test", strings.Join(config.TestFlags, " ")}
+	cmd := []string{c.ctx.Build.Compiler(), "test"}
+	cmd = append(cmd, buildTagsFlag()...)
+	cmd = append(cmd, config.TestFlags...)
 	if len(args) > 0 {
 		cmd = append(cmd, args...)
 	}
@@ -56,6 +84,20 @@ func (c *Command) Test(args []string, dir string) error {
 			for _, p := range pkgs {
 				testPkgs = append(testPkgs, pathutil.TrimGoPath(p.Dir))
 			}
+		case "mod":
+			for _, mod := range c.ctx.Build.ModuleRoots() {
+				pkgs, err := pathutil.FindAllPackage(mod, build.Default, nil, pathutil.ModeExcludeVendor|pathutil.ModeExcludeModule)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				for _, p := range pkgs {
+					pkgID, err := pathutil.ModuleImportPath(mod, p.Dir)
+					if err != nil {
+						return errors.WithStack(err)
+					}
+					testPkgs = append(testPkgs, pkgID)
+				}
+			}
 		case "gb":
 			// nothing to do
 		}
@@ -70,10 +112,37 @@ func (c *Command) Test(args []string, dir string) error {
 	cmd = append(cmd, testPkgs...)
 	log.Println(cmd)
 
+	return c.runTest(cmd, dir)
+}
+
+// runTest runs the test cmd on the "__GO_TEST__" terminal, or parses the
+// results of go test -json if config.TestJSON is enabled.
+// gb does not support the -json flag, so always use the terminal.
+func (c *Command) runTest(cmd []string, dir string) interface{} {
+	if config.TestJSON && c.ctx.Build.Tool != "gb" {
+		return c.testJSON(cmd, dir, false)
+	}
+	return c.runTestTerm(cmd, dir)
+}
+
+// testDir returns the working directory of the test command.
+func (c *Command) testDir(dir string) string {
+	switch c.ctx.Build.Tool {
+	case "mod":
+		// the go tool resolves the module import paths from the module or workspace root
+		return c.ctx.Build.Root()
+	default:
+		return pathutil.FindVCSRoot(dir)
+	}
+}
+
+// runTestTerm runs the cmd on the "__GO_TEST__" terminal.
+func (c *Command) runTestTerm(cmd []string, dir string) error {
 	if testTerm == nil {
 		testTerm = nvimutil.NewTerminal(c.Nvim, "__GO_TEST__", cmd, config.TerminalMode)
-		testTerm.Dir = pathutil.FindVCSRoot(dir)
 	}
+	testTerm.Dir = c.testDir(dir)
+	testTerm.Env = c.ctx.Build.TargetEnv()
 
 	if err := testTerm.Run(cmd); err != nil {
 		return nvimutil.ErrorWrap(c.Nvim, errors.WithStack(err))
@@ -82,6 +151,150 @@ func (c *Command) Test(args []string, dir string) error {
 	return nil
 }
 
+// ----------------------------------------------------------------------------
+// GoTestFunc
+
+type cmdTestFuncEval struct {
+	Cwd    string `msgpack:",array"`
+	File   string
+	Offset int
+}
+
+func (c *Command) cmdTestFunc(args []string, eval *cmdTestFuncEval) {
+	go func() {
+		c.errs.Delete("Test")
+
+		err := c.TestFunc(args, eval)
+		c.handleTestResult(err)
+	}()
+}
+
+// TestFunc run the test function or subtest of the current cursor position.
+func (c *Command) TestFunc(args []string, eval *cmdTestFuncEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoTestFunc")
+
+	if !strings.HasSuffix(eval.File, testSuffix) {
+		return nvimutil.Echoerr(c.Nvim, "GoTestFunc: current buffer is not the test file")
+	}
+
+	buf, err := c.Nvim.BufferLines(nvim.Buffer(c.ctx.BufNr), 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fset := token.NewFileSet()
+	f := parse(eval.File, fset, nvimutil.ToByteSlice(buf))
+	if f == nil {
+		return errors.New("couldn't parse of the current buffer")
+	}
+	offset := fset.File(f.Pos()).Pos(eval.Offset)
+
+	flags := testFuncFlags(f, offset)
+	if flags == nil {
+		return nvimutil.Echoerr(c.Nvim, "GoTestFunc: Not found the test function of current cursor")
+	}
+
+	dir := filepath.Dir(eval.File)
+	pkgID, err := pathutil.PackageID(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cmd := []string{c.ctx.Build.Compiler(), "test"}
+	cmd = append(cmd, buildTagsFlag()...)
+	cmd = append(cmd, config.TestFlags...)
+	cmd = append(cmd, flags...)
+	cmd = append(cmd, args...)
+	cmd = append(cmd, pkgID)
+	log.Println(cmd)
+
+	return c.runTest(cmd, dir)
+}
+
+// testFuncFlags returns the go test flags which runs the Test, Benchmark or
+// Example function enclosing the pos, and the t.Run subtests if any.
+// Returns nil if pos is not in the test function.
+func testFuncFlags(f *ast.File, pos token.Pos) []string {
+	var (
+		fn       string
+		subtests []string
+	)
+
+	// path is ordered from the innermost node to the *ast.File
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	for _, n := range path {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			if name, ok := subtestName(x); ok {
+				subtests = append([]string{name}, subtests...)
+			}
+		case *ast.FuncDecl:
+			if x.Recv == nil && x.Name != nil && isTestFunc(x.Name.Name) {
+				fn = x.Name.Name
+			}
+		}
+	}
+	if fn == "" {
+		return nil
+	}
+
+	return testRunFlags(fn, subtests...)
+}
+
+// testRunFlags returns the go test flags which runs only the fn test function
+// and the subtests.
+func testRunFlags(fn string, subtests ...string) []string {
+	pattern := "^" + fn + "$"
+	for _, name := range subtests {
+		pattern += "/^" + regexp.QuoteMeta(name) + "$"
+	}
+
+	if strings.HasPrefix(fn, "Benchmark") {
+		return []string{"-run", "^$", "-bench", pattern}
+	}
+	return []string{"-run", pattern}
+}
+
+// isTestFunc reports whether the name is the Test, Benchmark or Example function name.
+// The same as the go test, the name must be the prefix itself or followed by
+// the non-lowercase rune, and TestMain is not the test function.
+func isTestFunc(name string) bool {
+	if name == "TestMain" {
+		return false
+	}
+	for _, prefix := range []string{testPrefix, "Benchmark", "Example"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if len(name) == len(prefix) {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+		return !unicode.IsLower(r)
+	}
+	return false
+}
+
+// subtestName returns the subtest name if call is the x.Run("name", ...) call
+// expression with the string literal name.
+// The spaces of name are replaced by underscores the same as the testing package.
+func subtestName(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.Replace(name, " ", "_", -1), true
+}
+
 // ----------------------------------------------------------------------------
 // GoSwitchTest
 
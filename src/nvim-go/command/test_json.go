@@ -0,0 +1,514 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"nvim-go/nvimutil"
+	"nvim-go/pathutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// testEvent represents a event of the go test -json output.
+// See also: https://golang.org/cmd/test2json.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testResult represents a result of the test function or subtest.
+type testResult struct {
+	Package string
+	// Name full name of test, subtests are separated by a slash such as "TestFoo/bar".
+	Name string
+	// Action result action of test, "pass", "fail" or "skip". Empty if the test did not finish.
+	Action  string
+	Elapsed float64
+	Output  []string
+}
+
+// parseTestEvents parses the go test -json output stream.
+// The non JSON lines such as the build error messages are returned as the second value.
+func parseTestEvents(r io.Reader) ([]*testEvent, []byte, error) {
+	var (
+		events []*testEvent
+		other  bytes.Buffer
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			other.Write(line)
+			other.WriteByte('\n')
+			continue
+		}
+
+		ev := new(testEvent)
+		if err := json.Unmarshal(line, ev); err != nil {
+			other.Write(line)
+			other.WriteByte('\n')
+			continue
+		}
+
+		switch ev.Action {
+		case "build-output":
+			// since go1.24, the build output is also reported as the JSON event
+			other.WriteString(ev.Output)
+			continue
+		}
+		events = append(events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return events, other.Bytes(), nil
+}
+
+// collectTestResults collects the events to the each test results in order of appearance.
+func collectTestResults(events []*testEvent) []*testResult {
+	var (
+		results []*testResult
+		seen    = make(map[string]*testResult)
+	)
+
+	for _, ev := range events {
+		if ev.Test == "" {
+			continue
+		}
+
+		key := ev.Package + "\x00" + ev.Test
+		res, ok := seen[key]
+		if !ok {
+			res = &testResult{Package: ev.Package, Name: ev.Test}
+			seen[key] = res
+			results = append(results, res)
+		}
+
+		switch ev.Action {
+		case "output":
+			res.Output = append(res.Output, strings.TrimRight(ev.Output, "\n"))
+		case "pass", "fail", "skip":
+			res.Action = ev.Action
+			res.Elapsed = ev.Elapsed
+		}
+	}
+
+	return results
+}
+
+// testReport represents the results of go test -json for each package.
+type testReport struct {
+	Packages []*testPackage
+}
+
+// testPackage represents the test results of package.
+type testPackage struct {
+	// Name import path of package.
+	Name string
+	// Dir directory of package. Empty if could not resolve.
+	Dir     string
+	Action  string
+	Elapsed float64
+	// Output package level output such as the build failure and the summary of go test.
+	Output []string
+	Tests  []*testResult
+	// Decl position of the test function declarations.
+	Decl map[string]token.Position
+}
+
+// newTestReport groups the events to the each package results in order of appearance.
+// The packages that have no test files are omitted.
+func newTestReport(events []*testEvent) *testReport {
+	report := new(testReport)
+	pkgs := make(map[string]*testPackage)
+
+	for _, ev := range events {
+		pkg, ok := pkgs[ev.Package]
+		if !ok {
+			pkg = &testPackage{Name: ev.Package}
+			pkgs[ev.Package] = pkg
+			report.Packages = append(report.Packages, pkg)
+		}
+		if ev.Test != "" {
+			continue
+		}
+
+		switch ev.Action {
+		case "output":
+			pkg.Output = append(pkg.Output, strings.TrimRight(ev.Output, "\n"))
+		case "pass", "fail", "skip":
+			pkg.Action = ev.Action
+			pkg.Elapsed = ev.Elapsed
+		}
+	}
+
+	for _, res := range collectTestResults(events) {
+		pkgs[res.Package].Tests = append(pkgs[res.Package].Tests, res)
+	}
+
+	var filtered []*testPackage
+	for _, pkg := range report.Packages {
+		if pkg.Action == "skip" && len(pkg.Tests) == 0 {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	report.Packages = filtered
+
+	return report
+}
+
+// topLevelTest returns the top-level test function name of the name test.
+func topLevelTest(name string) string {
+	return strings.SplitN(name, "/", 2)[0]
+}
+
+// merge merges the r2 results into r.
+// The tests of r2 replace the all of results that have the same top-level test function in r.
+func (r *testReport) merge(r2 *testReport) {
+	for _, pkg2 := range r2.Packages {
+		var pkg *testPackage
+		for _, p := range r.Packages {
+			if p.Name == pkg2.Name {
+				pkg = p
+				break
+			}
+		}
+		// new package or the build failure
+		if pkg == nil {
+			r.Packages = append(r.Packages, pkg2)
+			continue
+		}
+		// no test events such as the -run matched nothing or the build failure,
+		// keeps the earlier results of the tests
+		if len(pkg2.Tests) == 0 {
+			pkg.Action = pkg2.Action
+			pkg.Output = pkg2.Output
+			continue
+		}
+
+		rerun := make(map[string][]*testResult)
+		var order []string
+		for _, res := range pkg2.Tests {
+			top := topLevelTest(res.Name)
+			if _, ok := rerun[top]; !ok {
+				order = append(order, top)
+			}
+			rerun[top] = append(rerun[top], res)
+		}
+
+		var tests []*testResult
+		for _, res := range pkg.Tests {
+			top := topLevelTest(res.Name)
+			results, ok := rerun[top]
+			if !ok {
+				tests = append(tests, res)
+				continue
+			}
+			// insert the rerun results at the position of the first old result
+			if results != nil {
+				tests = append(tests, results...)
+				rerun[top] = nil
+			}
+		}
+		for _, top := range order {
+			tests = append(tests, rerun[top]...)
+		}
+
+		pkg.Tests = tests
+		pkg.Output = pkg2.Output
+		pkg.Elapsed = pkg2.Elapsed
+		pkg.Dir, pkg.Decl = pkg2.Dir, pkg2.Decl
+		pkg.Action = "pass"
+		for _, res := range pkg.Tests {
+			if res.Action == "fail" {
+				pkg.Action = "fail"
+				break
+			}
+		}
+	}
+}
+
+// counts returns the number of passed and failed tests.
+func (r *testReport) counts() (passed, failed int) {
+	for _, pkg := range r.Packages {
+		for _, res := range pkg.Tests {
+			switch res.Action {
+			case "pass":
+				passed++
+			case "fail":
+				failed++
+			}
+		}
+	}
+	return passed, failed
+}
+
+// errors returns the errorlist of the failed tests.
+func (r *testReport) errors() []*nvim.QuickfixError {
+	var errlist []*nvim.QuickfixError
+	for _, pkg := range r.Packages {
+		if pkg.Dir == "" {
+			continue
+		}
+		errlist = append(errlist, testResultErrors(pkg.Tests, pkg.Dir, pkg.Decl)...)
+	}
+	return errlist
+}
+
+// signs returns the sign positions of the top-level test functions.
+func (r *testReport) signs() []testSignPos {
+	var signs []testSignPos
+	for _, pkg := range r.Packages {
+		for _, res := range pkg.Tests {
+			if pos, ok := pkg.Decl[res.Name]; ok {
+				signs = append(signs, testSignPos{Action: res.Action, Pos: pos})
+			}
+		}
+	}
+	return signs
+}
+
+// testOutputRe matches the t.Errorf like log output such as "    foo_test.go:12: message".
+var testOutputRe = regexp.MustCompile(`^\s+([^\s:]+\.go):(\d+): (.*)$`)
+
+// testResultErrors converts the log output of the failed tests to the errorlist.
+// dir is the package directory of test files, and decl is the position of the
+// test function declarations which is used if the failed test has not any log output.
+func testResultErrors(results []*testResult, dir string, decl map[string]token.Position) []*nvim.QuickfixError {
+	var errlist []*nvim.QuickfixError
+	failed := make(map[string]bool) // top-level test name, whether have the error location
+
+	for _, res := range results {
+		if res.Action != "fail" {
+			continue
+		}
+
+		top := topLevelTest(res.Name)
+		if _, ok := failed[top]; !ok {
+			failed[top] = false
+		}
+		for _, out := range res.Output {
+			m := testOutputRe.FindStringSubmatch(out)
+			if m == nil {
+				continue
+			}
+			line, err := strconv.Atoi(m[2])
+			if err != nil {
+				continue
+			}
+			errlist = append(errlist, &nvim.QuickfixError{
+				FileName: filepath.Join(dir, m[1]),
+				LNum:     line,
+				Text:     fmt.Sprintf("%s: %s", res.Name, m[3]),
+			})
+			failed[top] = true
+		}
+	}
+
+	// fallback to the test function declaration such as panic or timeout
+	for _, res := range results {
+		if found, ok := failed[res.Name]; !ok || found {
+			continue
+		}
+		if pos, ok := decl[res.Name]; ok {
+			errlist = append(errlist, &nvim.QuickfixError{
+				FileName: pos.Filename,
+				LNum:     pos.Line,
+				Col:      pos.Column,
+				Text:     fmt.Sprintf("%s: FAIL", res.Name),
+			})
+		}
+	}
+
+	return errlist
+}
+
+// testFuncDecls returns the position of the test function declarations in the dir package test files.
+func testFuncDecls(dir string) map[string]token.Position {
+	decl := make(map[string]token.Position)
+
+	fset := token.NewFileSet()
+	filter := func(fi os.FileInfo) bool { return strings.HasSuffix(fi.Name(), testSuffix) }
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return decl
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, d := range f.Decls {
+				if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && isTestFunc(fn.Name.Name) {
+					decl[fn.Name.Name] = fset.Position(fn.Name.Pos())
+				}
+			}
+		}
+	}
+
+	return decl
+}
+
+var (
+	testPassSign *nvimutil.Sign
+	testFailSign *nvimutil.Sign
+
+	// testSigns placed test sign ids for each file.
+	testSigns = make(map[string][]int)
+)
+
+// testSignID is the first sign id of test result signs.
+const testSignID = 5000
+
+// testSignPos represents a result action and the position of test function declaration.
+type testSignPos struct {
+	Action string
+	Pos    token.Position
+}
+
+// placeTestSigns places the pass or fail signs to the top-level test function declarations.
+func (c *Command) placeTestSigns(signs []testSignPos) error {
+	if testPassSign == nil || testFailSign == nil {
+		var err error
+		testPassSign, err = nvimutil.NewSign(c.Nvim, "nvim_go_test_pass", nvimutil.PassSymbol, "GoTestPassSign", "")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		testFailSign, err = nvimutil.NewSign(c.Nvim, "nvim_go_test_fail", nvimutil.FailSymbol, "GoTestFailSign", "")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for file, ids := range testSigns {
+		for _, id := range ids {
+			testPassSign.Unplace(c.Nvim, id, file)
+		}
+	}
+	testSigns = make(map[string][]int)
+
+	id := testSignID
+	for _, s := range signs {
+		var sign *nvimutil.Sign
+		switch s.Action {
+		case "pass":
+			sign = testPassSign
+		case "fail":
+			sign = testFailSign
+		default:
+			continue
+		}
+		if err := sign.Place(c.Nvim, id, s.Pos.Line, s.Pos.Filename, false); err != nil {
+			return err
+		}
+		testSigns[s.Pos.Filename] = append(testSigns[s.Pos.Filename], id)
+		id++
+	}
+
+	return nil
+}
+
+// testPackageDir returns the directory of importPath package.
+func (c *Command) testPackageDir(importPath string) (string, bool) {
+	if c.ctx.Build.Tool == "mod" {
+		for _, mod := range c.ctx.Build.ModuleRoots() {
+			if dir, ok := pathutil.ModuleDir(mod, importPath); ok {
+				return dir, true
+			}
+		}
+		return "", false
+	}
+
+	pkg, err := build.Default.Import(importPath, "", build.FindOnly)
+	if err != nil {
+		return "", false
+	}
+	return pkg.Dir, true
+}
+
+// testJSON runs the go test cmd with -json flag, and converts the failed test
+// results to the errorlist. Also places the pass or fail signs to test functions.
+// If merge is true, the results are merged into the last test results, such as rerun of the some tests.
+func (c *Command) testJSON(cmd []string, dir string, merge bool) interface{} {
+	args := append([]string{"test", "-json"}, cmd[2:]...)
+	testCmd := exec.Command(cmd[0], args...)
+	testCmd.Dir = c.testDir(dir)
+	testCmd.Env = append(os.Environ(), c.ctx.Build.TargetEnv()...)
+
+	var stdout, stderr bytes.Buffer
+	testCmd.Stdout = &stdout
+	testCmd.Stderr = &stderr
+
+	if err := testCmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return errors.WithStack(err)
+		}
+	}
+
+	events, other, err := parseTestEvents(&stdout)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	errlist, err := nvimutil.ParseError(append(other, stderr.Bytes()...), testCmd.Dir, &c.ctx.Build, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	report := newTestReport(events)
+	for _, pkg := range report.Packages {
+		if pkgDir, ok := c.testPackageDir(pkg.Name); ok {
+			pkg.Dir = pkgDir
+			pkg.Decl = testFuncDecls(pkgDir)
+		}
+	}
+
+	testReportMu.Lock()
+	if merge && lastTestReport != nil {
+		lastTestReport.merge(report)
+		report = lastTestReport
+	}
+	lastTestReport, lastTestDir = report, dir
+	errlist = append(errlist, report.errors()...)
+	signs := report.signs()
+	passed, failed := report.counts()
+	testReportMu.Unlock()
+
+	if err := c.placeTestSigns(signs); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := c.refreshTestResults(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(errlist) > 0 {
+		return errlist
+	}
+	if failed > 0 {
+		return nvimutil.Echoerr(c.Nvim, "GoTest: %d tests failed", failed)
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoTest", fmt.Sprintf("%d tests passed", passed))
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+const testJSONOutput = `{"Action":"run","Package":"foo.org/foo","Test":"TestFoo"}
+{"Action":"output","Package":"foo.org/foo","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"run","Package":"foo.org/foo","Test":"TestFoo/bar"}
+{"Action":"output","Package":"foo.org/foo","Test":"TestFoo/bar","Output":"    foo_test.go:12: got 1, want 2\n"}
+{"Action":"output","Package":"foo.org/foo","Test":"TestFoo/bar","Output":"    --- FAIL: TestFoo/bar (0.00s)\n"}
+{"Action":"fail","Package":"foo.org/foo","Test":"TestFoo/bar","Elapsed":0.01}
+{"Action":"fail","Package":"foo.org/foo","Test":"TestFoo","Elapsed":0.02}
+{"Action":"run","Package":"foo.org/foo","Test":"TestPanic"}
+{"Action":"output","Package":"foo.org/foo","Test":"TestPanic","Output":"panic: boom\n"}
+{"Action":"fail","Package":"foo.org/foo","Test":"TestPanic","Elapsed":0}
+{"Action":"run","Package":"foo.org/foo","Test":"TestPass"}
+{"Action":"pass","Package":"foo.org/foo","Test":"TestPass","Elapsed":0}
+{"Action":"fail","Package":"foo.org/foo","Elapsed":0.03}
+# foo.org/bar
+bar.go:3:2: undefined: baz
+`
+
+func TestParseTestEvents(t *testing.T) {
+	events, other, err := parseTestEvents(strings.NewReader(testJSONOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(events), 13; got != want {
+		t.Errorf("parseTestEvents() len(events) = %v, want %v", got, want)
+	}
+	if got, want := string(other), "# foo.org/bar\nbar.go:3:2: undefined: baz\n"; got != want {
+		t.Errorf("parseTestEvents() other = %q, want %q", got, want)
+	}
+
+	results := collectTestResults(events)
+	var got []string
+	for _, res := range results {
+		got = append(got, res.Name+":"+res.Action)
+	}
+	want := []string{"TestFoo:fail", "TestFoo/bar:fail", "TestPanic:fail", "TestPass:pass"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectTestResults() = %v, want %v", got, want)
+	}
+}
+
+func TestTestResultErrors(t *testing.T) {
+	events, _, err := parseTestEvents(strings.NewReader(testJSONOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl := map[string]token.Position{
+		"TestFoo":   {Filename: "/foo/foo_test.go", Line: 5, Column: 6},
+		"TestPanic": {Filename: "/foo/foo_test.go", Line: 20, Column: 6},
+		"TestPass":  {Filename: "/foo/foo_test.go", Line: 30, Column: 6},
+	}
+
+	got := testResultErrors(collectTestResults(events), "/foo", decl)
+	want := []*nvim.QuickfixError{
+		{FileName: "/foo/foo_test.go", LNum: 12, Text: "TestFoo/bar: got 1, want 2"},
+		{FileName: "/foo/foo_test.go", LNum: 20, Col: 6, Text: "TestPanic: FAIL"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, e := range got {
+			t.Logf("%#v", e)
+		}
+		t.Errorf("testResultErrors() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/config"
+	"nvim-go/nvimutil"
+)
+
+// ----------------------------------------------------------------------------
+// GoTestResults
+
+const (
+	// testResultsBufName buffer name of the test results.
+	testResultsBufName = "__GO_TEST_RESULTS__"
+
+	testExpandSymbol   = "\u25bc" // ▼
+	testCollapseSymbol = "\u25b6" // ▶
+	testPassMark       = nvimutil.PassSymbol
+	testFailMark       = nvimutil.FailSymbol
+	testSkipMark       = "\u21b7" // ↷
+	testRunMark        = "\u2026" // …
+	testOutputMark     = "\u2502" // │
+)
+
+var (
+	testReportMu sync.Mutex
+	// lastTestReport results of the last go test -json.
+	lastTestReport *testReport
+	// lastTestDir directory of the last go test -json.
+	lastTestDir string
+
+	testResultsBuf = newScratchBuffer(testResultsBufName, nvimutil.FiletypeGoTest, "botright split")
+	// testResultsLines each line of the test results buffer.
+	testResultsLines []*testLine
+	// testResultsExpanded expanded state of the test results tree that toggled by user.
+	testResultsExpanded = make(map[string]bool)
+)
+
+// testLine represents a line of the test results buffer.
+type testLine struct {
+	Package *testPackage
+	// Test nil if the package line.
+	Test *testResult
+	// Foldable whether the line has the children or output.
+	Foldable bool
+	Expanded bool
+}
+
+// key returns the unique key of the line for the expanded state.
+func (l *testLine) key() string {
+	if l.Test == nil {
+		return l.Package.Name
+	}
+	return l.Package.Name + "\x00" + l.Test.Name
+}
+
+// position returns the jump destination position of the line.
+// It is the first error location of the test, or the test function declaration.
+func (l *testLine) position() (token.Position, bool) {
+	if l.Test == nil || l.Package.Dir == "" {
+		return token.Position{}, false
+	}
+
+	for _, out := range l.Test.Output {
+		if m := testOutputRe.FindStringSubmatch(out); m != nil {
+			line, _ := strconv.Atoi(m[2])
+			return token.Position{Filename: filepath.Join(l.Package.Dir, m[1]), Line: line}, true
+		}
+	}
+
+	pos, ok := l.Package.Decl[topLevelTest(l.Test.Name)]
+	return pos, ok
+}
+
+// testMark returns the mark of test result action.
+func testMark(action string) string {
+	switch action {
+	case "pass":
+		return testPassMark
+	case "fail":
+		return testFailMark
+	case "skip":
+		return testSkipMark
+	default:
+		return testRunMark
+	}
+}
+
+// isTestSummary reports whether the output line is the summary line of go test
+// such as "=== RUN", "--- FAIL" or "PASS".
+func isTestSummary(out string) bool {
+	trimmed := strings.TrimSpace(out)
+	return strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") || trimmed == "PASS" || trimmed == "FAIL" || trimmed == ""
+}
+
+// renderTestReport renders the test results tree of packages, tests and
+// subtests, and returns the buffer lines and the line information.
+// The line information is nil for the non tree node line.
+// The packages and failed tests are expanded by default.
+func renderTestReport(r *testReport, expanded map[string]bool) ([]string, []*testLine) {
+	var (
+		lines []string
+		infos []*testLine
+	)
+
+	passed, failed := r.counts()
+	lines = append(lines, fmt.Sprintf("GoTest: %d passed, %d failed", passed, failed))
+	infos = append(infos, nil)
+
+	output := func(depth int, out []string, info *testLine) {
+		for _, o := range out {
+			if isTestSummary(o) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s%s %s", strings.Repeat("  ", depth+1), testOutputMark, strings.TrimSpace(o)))
+			infos = append(infos, info)
+		}
+	}
+
+	node := func(depth int, info *testLine, name, action string, elapsed float64) {
+		fold := "  "
+		if info.Foldable {
+			fold = testCollapseSymbol + " "
+			if info.Expanded {
+				fold = testExpandSymbol + " "
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s%s%s %s (%.2fs)", strings.Repeat("  ", depth), fold, testMark(action), name, elapsed))
+		infos = append(infos, info)
+	}
+
+	isExpanded := func(info *testLine, def bool) bool {
+		if e, ok := expanded[info.key()]; ok {
+			return e
+		}
+		return def
+	}
+
+	for _, pkg := range r.Packages {
+		children := make(map[string][]*testResult)
+		for _, res := range pkg.Tests {
+			parent := ""
+			if i := strings.LastIndex(res.Name, "/"); i >= 0 {
+				parent = res.Name[:i]
+			}
+			children[parent] = append(children[parent], res)
+		}
+
+		var walk func(depth int, res *testResult)
+		walk = func(depth int, res *testResult) {
+			info := &testLine{Package: pkg, Test: res}
+			var out []string
+			for _, o := range res.Output {
+				if !isTestSummary(o) {
+					out = append(out, o)
+				}
+			}
+			info.Foldable = len(children[res.Name]) > 0 || len(out) > 0
+			info.Expanded = info.Foldable && isExpanded(info, res.Action == "fail")
+
+			name := res.Name[strings.LastIndex(res.Name, "/")+1:]
+			node(depth, info, name, res.Action, res.Elapsed)
+			if !info.Expanded {
+				return
+			}
+			output(depth, out, info)
+			for _, child := range children[res.Name] {
+				walk(depth+1, child)
+			}
+		}
+
+		info := &testLine{Package: pkg, Foldable: true}
+		info.Expanded = isExpanded(info, true)
+		node(0, info, pkg.Name, pkg.Action, pkg.Elapsed)
+		if !info.Expanded {
+			continue
+		}
+		for _, res := range children[""] {
+			walk(1, res)
+		}
+		output(0, pkg.Output, info)
+	}
+
+	return lines, infos
+}
+
+func (c *Command) cmdTestResults() {
+	go func() {
+		if err := c.TestResults(); err != nil {
+			nvimutil.ErrorWrap(c.Nvim, err)
+		}
+	}()
+}
+
+// TestResults opens the buffer of the last test results tree.
+func (c *Command) TestResults() error {
+	defer nvimutil.Profile(time.Now(), "GoTestResults")
+
+	testReportMu.Lock()
+	report := lastTestReport
+	testReportMu.Unlock()
+	if report == nil {
+		return nvimutil.Echoerr(c.Nvim, "GoTestResults: no test results. Run Gotest with g:go#test#json")
+	}
+
+	rpc := func(action string) string {
+		return fmt.Sprintf(":<C-u>call rpcrequest(%d, 'GoTestResultsAction', '%s', line('.'))<CR>", config.ChannelID, action)
+	}
+	nnoremap := map[string]string{
+		"<CR>": rpc("jump"),
+		"o":    rpc("toggle"),
+		"r":    rpc("rerun"),
+		"R":    rpc("rerun_failed"),
+		"q":    ":<C-u>quit<CR>",
+	}
+	if _, err := testResultsBuf.open(c, nnoremap); err != nil {
+		return err
+	}
+
+	return c.refreshTestResults()
+}
+
+// refreshTestResults re-renders the test results buffer if opened.
+func (c *Command) refreshTestResults() error {
+	if !testResultsBuf.isValid(c) {
+		return nil
+	}
+
+	testReportMu.Lock()
+	if lastTestReport == nil {
+		testReportMu.Unlock()
+		return nil
+	}
+	var lines []string
+	lines, testResultsLines = renderTestReport(lastTestReport, testResultsExpanded)
+	testReportMu.Unlock()
+
+	return testResultsBuf.setLines(c, lines)
+}
+
+// testResultsAction handles the mapping action of the test results buffer.
+func (c *Command) testResultsAction(action string, line int) error {
+	testReportMu.Lock()
+	var l *testLine
+	if line >= 1 && line <= len(testResultsLines) {
+		l = testResultsLines[line-1]
+	}
+	report, dir := lastTestReport, lastTestDir
+	testReportMu.Unlock()
+
+	switch action {
+	case "toggle":
+		if l == nil || !l.Foldable {
+			return nil
+		}
+		testReportMu.Lock()
+		testResultsExpanded[l.key()] = !l.Expanded
+		testReportMu.Unlock()
+		return c.refreshTestResults()
+
+	case "jump":
+		if l == nil {
+			return nil
+		}
+		pos, ok := l.position()
+		if !ok {
+			return nil
+		}
+		batch := c.Nvim.NewBatch()
+		batch.Command("wincmd p")
+		batch.Command(fmt.Sprintf("execute 'edit' fnameescape(%s)", strconv.Quote(pos.Filename)))
+		batch.Command(fmt.Sprintf("call cursor(%d, %d)", pos.Line, pos.Column))
+		batch.Command("normal! zz")
+		return batch.Execute()
+
+	case "rerun":
+		if l == nil {
+			return nil
+		}
+		cmd := []string{c.ctx.Build.Compiler(), "test"}
+		cmd = append(cmd, buildTagsFlag()...)
+		cmd = append(cmd, config.TestFlags...)
+		if l.Test != nil {
+			names := strings.Split(l.Test.Name, "/")
+			cmd = append(cmd, testRunFlags(names[0], names[1:]...)...)
+		}
+		cmd = append(cmd, l.Package.Name)
+		go c.rerunTest(cmd, dir)
+
+	case "rerun_failed":
+		if report == nil {
+			return nil
+		}
+		cmd, ok := failedTestCmd(report, c.ctx.Build.Compiler())
+		if !ok {
+			return nvimutil.Echomsg(c.Nvim, "GoTestResults: no failed tests")
+		}
+		go c.rerunTest(cmd, dir)
+	}
+
+	return nil
+}
+
+// rerunTest reruns the cmd and merges the results into the last test results.
+func (c *Command) rerunTest(cmd []string, dir string) {
+	c.errs.Delete("Test")
+
+	err := c.testJSON(cmd, dir, true)
+	c.handleTestResult(err)
+}
+
+// failedTestCmd returns the go test command which reruns the failed top-level
+// tests and packages of report.
+func failedTestCmd(report *testReport, compiler string) ([]string, bool) {
+	var (
+		pkgs  []string
+		names []string
+		seen  = make(map[string]bool)
+	)
+
+	testReportMu.Lock()
+	defer testReportMu.Unlock()
+	for _, pkg := range report.Packages {
+		if pkg.Action != "fail" {
+			continue
+		}
+		pkgs = append(pkgs, pkg.Name)
+		for _, res := range pkg.Tests {
+			top := topLevelTest(res.Name)
+			if res.Action == "fail" && !seen[top] {
+				seen[top] = true
+				names = append(names, top)
+			}
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, false
+	}
+
+	cmd := []string{compiler, "test"}
+	cmd = append(cmd, buildTagsFlag()...)
+	cmd = append(cmd, config.TestFlags...)
+	if len(names) > 0 {
+		cmd = append(cmd, "-run", "^("+strings.Join(names, "|")+")$")
+	}
+
+	return append(cmd, pkgs...), true
+}
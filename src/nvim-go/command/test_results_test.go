@@ -0,0 +1,152 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testReportFromJSON(t *testing.T, s string) *testReport {
+	events, _, err := parseTestEvents(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestReport(events)
+}
+
+func TestRenderTestReport(t *testing.T) {
+	report := testReportFromJSON(t, testJSONOutput)
+
+	tests := []struct {
+		name     string
+		expanded map[string]bool
+		want     []string
+	}{
+		{
+			name:     "default",
+			expanded: map[string]bool{},
+			want: []string{
+				"GoTest: 1 passed, 3 failed",
+				"▼ ✖ foo.org/foo (0.03s)",
+				"  ▼ ✖ TestFoo (0.02s)",
+				"    ▼ ✖ bar (0.01s)",
+				"      │ foo_test.go:12: got 1, want 2",
+				"  ▼ ✖ TestPanic (0.00s)",
+				"    │ panic: boom",
+				"    ✔ TestPass (0.00s)",
+			},
+		},
+		{
+			name:     "collapse TestFoo",
+			expanded: map[string]bool{"foo.org/foo\x00TestFoo": false},
+			want: []string{
+				"GoTest: 1 passed, 3 failed",
+				"▼ ✖ foo.org/foo (0.03s)",
+				"  ▶ ✖ TestFoo (0.02s)",
+				"  ▼ ✖ TestPanic (0.00s)",
+				"    │ panic: boom",
+				"    ✔ TestPass (0.00s)",
+			},
+		},
+		{
+			name:     "collapse package",
+			expanded: map[string]bool{"foo.org/foo": false},
+			want: []string{
+				"GoTest: 1 passed, 3 failed",
+				"▶ ✖ foo.org/foo (0.03s)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, infos := renderTestReport(report, tt.expanded)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderTestReport() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if len(infos) != len(got) {
+				t.Errorf("renderTestReport() len(infos) = %d, want %d", len(infos), len(got))
+			}
+		})
+	}
+}
+
+func TestTestReportMerge(t *testing.T) {
+	type args struct {
+		rerun string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantTests  []string
+		wantAction string
+		wantOutput []string
+	}{
+		{
+			name: "rerun the failed tests",
+			args: args{rerun: `{"Action":"run","Package":"foo.org/foo","Test":"TestFoo"}
+{"Action":"run","Package":"foo.org/foo","Test":"TestFoo/bar"}
+{"Action":"pass","Package":"foo.org/foo","Test":"TestFoo/bar","Elapsed":0}
+{"Action":"pass","Package":"foo.org/foo","Test":"TestFoo","Elapsed":0}
+{"Action":"output","Package":"foo.org/foo","Output":"ok\n"}
+{"Action":"pass","Package":"foo.org/foo","Elapsed":0.01}
+`},
+			wantTests:  []string{"TestFoo:pass", "TestFoo/bar:pass", "TestPanic:fail", "TestPass:pass"},
+			wantAction: "fail",
+			wantOutput: []string{"ok"},
+		},
+		{
+			name: "rerun without the test events",
+			args: args{rerun: `{"Action":"output","Package":"foo.org/foo","Output":"testing: warning: no tests to run\n"}
+{"Action":"pass","Package":"foo.org/foo","Elapsed":0.01}
+`},
+			wantTests:  []string{"TestFoo:fail", "TestFoo/bar:fail", "TestPanic:fail", "TestPass:pass"},
+			wantAction: "pass",
+			wantOutput: []string{"testing: warning: no tests to run"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			report := testReportFromJSON(t, testJSONOutput)
+			report.merge(testReportFromJSON(t, tt.args.rerun))
+
+			pkg := report.Packages[0]
+			var got []string
+			for _, res := range pkg.Tests {
+				got = append(got, res.Name+":"+res.Action)
+			}
+			if !reflect.DeepEqual(got, tt.wantTests) {
+				t.Errorf("merge() tests = %v, want %v", got, tt.wantTests)
+			}
+			if pkg.Action != tt.wantAction {
+				t.Errorf("merge() package action = %v, want %v", pkg.Action, tt.wantAction)
+			}
+			if !reflect.DeepEqual(pkg.Output, tt.wantOutput) {
+				t.Errorf("merge() package output = %v, want %v", pkg.Output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestFailedTestCmd(t *testing.T) {
+	report := testReportFromJSON(t, testJSONOutput)
+	report.merge(testReportFromJSON(t, `{"Action":"run","Package":"foo.org/foo","Test":"TestFoo"}
+{"Action":"pass","Package":"foo.org/foo","Test":"TestFoo","Elapsed":0}
+{"Action":"pass","Package":"foo.org/foo","Elapsed":0.01}
+`))
+
+	cmd, ok := failedTestCmd(report, "go")
+	if !ok {
+		t.Fatal("failedTestCmd() = false, want true")
+	}
+	if got, want := strings.Join(cmd[len(cmd)-3:], " "), "-run ^(TestPanic)$ foo.org/foo"; got != want {
+		t.Errorf("failedTestCmd() = %v, want %v", got, want)
+	}
+}
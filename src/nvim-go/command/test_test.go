@@ -0,0 +1,127 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFuncSrc = `package foo
+
+import "testing"
+
+func helper() {}
+
+func TestFoo(t *testing.T) {
+	// TestFoo body
+	t.Run("bar baz", func(t *testing.T) {
+		// bar baz body
+		t.Run("qux.1", func(t *testing.T) {
+			// qux body
+		})
+	})
+}
+
+func BenchmarkFoo(b *testing.B) {
+	// BenchmarkFoo body
+}
+
+func ExampleFoo() {
+	// ExampleFoo body
+}
+`
+
+func TestTestFuncFlags(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo_test.go", testFuncSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	posOf := func(s string) token.Pos {
+		return fset.File(f.Pos()).Pos(strings.Index(testFuncSrc, s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   []string
+	}{
+		{
+			name:   "test function",
+			cursor: "// TestFoo body",
+			want:   []string{"-run", "^TestFoo$"},
+		},
+		{
+			name:   "subtest",
+			cursor: "// bar baz body",
+			want:   []string{"-run", `^TestFoo$/^bar_baz$`},
+		},
+		{
+			name:   "nested subtest",
+			cursor: "// qux body",
+			want:   []string{"-run", `^TestFoo$/^bar_baz$/^qux\.1$`},
+		},
+		{
+			name:   "benchmark function",
+			cursor: "// BenchmarkFoo body",
+			want:   []string{"-run", "^$", "-bench", "^BenchmarkFoo$"},
+		},
+		{
+			name:   "example function",
+			cursor: "// ExampleFoo body",
+			want:   []string{"-run", "^ExampleFoo$"},
+		},
+		{
+			name:   "not test function",
+			cursor: "func helper() {}",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := testFuncFlags(f, posOf(tt.cursor)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("testFuncFlags(%q) = %v, want %v", tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTestFunc(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{name: "test", args: args{name: "TestFoo"}, want: true},
+		{name: "test with underscore", args: args{name: "Test_foo"}, want: true},
+		{name: "test prefix only", args: args{name: "Test"}, want: true},
+		{name: "benchmark", args: args{name: "BenchmarkFoo"}, want: true},
+		{name: "example", args: args{name: "ExampleFoo"}, want: true},
+		{name: "package example", args: args{name: "Example"}, want: true},
+		{name: "TestMain", args: args{name: "TestMain"}, want: false},
+		{name: "lowercase after Test", args: args{name: "Testify"}, want: false},
+		{name: "lowercase after Benchmark", args: args{name: "Benchmarker"}, want: false},
+		{name: "lowercase after Example", args: args{name: "Examples"}, want: false},
+		{name: "not test", args: args{name: "helper"}, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isTestFunc(tt.args.name); got != tt.want {
+				t.Errorf("isTestFunc(%v) = %v, want %v", tt.args.name, got, tt.want)
+			}
+		})
+	}
+}
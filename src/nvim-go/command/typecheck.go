@@ -0,0 +1,209 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"go/build"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nvim-go/nvimutil"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// TypeCheck type-checks the current buffers package in-process with go/types
+// instead of spawn the go build, uses the current buffer contents as the overlay.
+// Returns the errorlist of the type errors.
+func (c *Command) TypeCheck(eval *CmdBuildEval) interface{} {
+	defer nvimutil.Profile(time.Now(), "GoTypeCheck")
+
+	buf, err := c.Nvim.BufferLines(nvim.Buffer(c.ctx.BufNr), 0, -1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	overlay := map[string][]byte{eval.File: bytes.Join(buf, []byte{'\n'})}
+
+	errlist, err := typeCheck(context.Background(), eval.File, overlay)
+	if err != nil {
+		return err
+	}
+	if len(errlist) > 0 {
+		return errlist
+	}
+
+	return nvimutil.EchoSuccess(c.Nvim, "GoBuild", "mode: typecheck")
+}
+
+// typeCheck type-checks the package of file with the overlay contents, and
+// returns the parse and type errors of the package files.
+// The function bodies of the imported packages are not type-checked.
+// The loading of the imported packages is stopped as soon as ctx is done, and
+// returns the ctx error.
+func typeCheck(ctx context.Context, file string, overlay map[string][]byte) ([]*nvim.QuickfixError, error) {
+	// the overlay is only for the files of the target package. the go/build
+	// skips the module resolution if the OpenFile or ReadDir is set, so the
+	// imported packages are resolved by the non-overlay context
+	ctxt := buildutil.OverlayContext(buildTagsContext(), overlay)
+	importCtxt := buildTagsContext()
+
+	dir := filepath.Dir(file)
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		if _, nogo := err.(*build.NoGoError); nogo {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	path, files := typeCheckFiles(bp, file)
+
+	// the C pseudo package is faked, and do not run the cgo preprocessing
+	// of the imported packages, the pure Go files are enough for the type-check
+	nocgo := *ctxt
+	nocgo.CgoEnabled = false
+	importCtxt.CgoEnabled = false
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	conf := loader.Config{
+		Build:       &nocgo,
+		Cwd:         dir,
+		AllowErrors: true,
+		// AllErrors makes the parser always return an AST instead of
+		// bailing out after 10 errors and returning an empty ast.File.
+		ParserMode: parser.AllErrors,
+		TypeChecker: types.Config{
+			FakeImportC: true,
+			Error: func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		},
+		TypeCheckFuncBodies: func(p string) bool { return p == path && ctx.Err() == nil },
+		FindPackage: func(_ *build.Context, importPath, fromDir string, mode build.ImportMode) (*build.Package, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// the go list of the module resolution runs in the Dir
+			ictxt := *importCtxt
+			ictxt.Dir = fromDir
+			return ictxt.Import(importPath, fromDir, mode)
+		},
+	}
+	conf.CreateFromFilenames(path, files...)
+	_, err = conf.Load()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	targets := make(map[string]bool)
+	for _, f := range files {
+		targets[f] = true
+	}
+
+	return typeErrors(errs, targets), nil
+}
+
+// typeCheckFiles returns the package path and the absolute file names to
+// type-check the package bp that includes file.
+// The test files are included if file is the test file, and the external
+// test package is type-checked if file is the external test file.
+func typeCheckFiles(bp *build.Package, file string) (string, []string) {
+	base := filepath.Base(file)
+
+	contains := func(names []string) bool {
+		for _, name := range names {
+			if name == base {
+				return true
+			}
+		}
+		return false
+	}
+
+	path := bp.ImportPath
+	var names []string
+	switch {
+	case contains(bp.XTestGoFiles):
+		path += "_test"
+		names = bp.XTestGoFiles
+	case contains(bp.TestGoFiles):
+		names = append(append(append(names, bp.GoFiles...), bp.CgoFiles...), bp.TestGoFiles...)
+	default:
+		names = append(append(names, bp.GoFiles...), bp.CgoFiles...)
+	}
+
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = filepath.Join(bp.Dir, name)
+	}
+
+	return path, files
+}
+
+// typeErrors converts the parse and type errors in the targets files to the errorlist.
+func typeErrors(errs []error, targets map[string]bool) []*nvim.QuickfixError {
+	var (
+		errlist []*nvim.QuickfixError
+		seen    = make(map[string]bool)
+	)
+	add := func(pos token.Position, msg string) {
+		if !targets[pos.Filename] {
+			return
+		}
+		key := pos.String() + msg
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		errlist = append(errlist, &nvim.QuickfixError{
+			FileName: pos.Filename,
+			LNum:     pos.Line,
+			Col:      pos.Column,
+			Text:     msg,
+		})
+	}
+
+	for _, err := range errs {
+		switch e := err.(type) {
+		case types.Error:
+			add(e.Fset.Position(e.Pos), strings.TrimSpace(e.Msg))
+		case scanner.ErrorList:
+			for _, se := range e {
+				add(se.Pos, se.Msg)
+			}
+		case *scanner.Error:
+			add(e.Pos, e.Msg)
+		}
+	}
+	sort.SliceStable(errlist, func(i, j int) bool {
+		if errlist[i].FileName != errlist[j].FileName {
+			return errlist[i].FileName < errlist[j].FileName
+		}
+		if errlist[i].LNum != errlist[j].LNum {
+			return errlist[i].LNum < errlist[j].LNum
+		}
+		return errlist[i].Col < errlist[j].Col
+	})
+
+	return errlist
+}
@@ -0,0 +1,175 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestTypeCheckFiles(t *testing.T) {
+	bp := &build.Package{
+		Dir:          "/src/foo",
+		ImportPath:   "foo.org/foo",
+		GoFiles:      []string{"foo.go"},
+		CgoFiles:     []string{"cgo.go"},
+		TestGoFiles:  []string{"foo_test.go"},
+		XTestGoFiles: []string{"example_test.go"},
+	}
+
+	type args struct {
+		file string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantPath  string
+		wantFiles []string
+	}{
+		{
+			name:      "package file",
+			args:      args{file: "/src/foo/foo.go"},
+			wantPath:  "foo.org/foo",
+			wantFiles: []string{"/src/foo/foo.go", "/src/foo/cgo.go"},
+		},
+		{
+			name:      "test file",
+			args:      args{file: "/src/foo/foo_test.go"},
+			wantPath:  "foo.org/foo",
+			wantFiles: []string{"/src/foo/foo.go", "/src/foo/cgo.go", "/src/foo/foo_test.go"},
+		},
+		{
+			name:      "external test file",
+			args:      args{file: "/src/foo/example_test.go"},
+			wantPath:  "foo.org/foo_test",
+			wantFiles: []string{"/src/foo/example_test.go"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path, files := typeCheckFiles(bp, tt.args.file)
+			if path != tt.wantPath {
+				t.Errorf("typeCheckFiles(%v) path = %v, want %v", tt.args.file, path, tt.wantPath)
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("typeCheckFiles(%v) files = %v, want %v", tt.args.file, files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestTypeCheck(t *testing.T) {
+	type args struct {
+		file    string
+		overlay map[string][]byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*nvim.QuickfixError
+		wantErr bool
+	}{
+		{
+			name:    "no errors",
+			args:    args{file: astdumpMain},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "overlay type error",
+			args: args{
+				file:    astdumpMain,
+				overlay: map[string][]byte{astdumpMain: []byte("package main\n\nfunc main() {\n\tvar x int = \"s\"\n\t_ = x\n}\n")},
+			},
+			want: []*nvim.QuickfixError{
+				{FileName: astdumpMain, LNum: 4, Col: 14, Text: `cannot use "s" (untyped string constant) as int value in variable declaration`},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse error",
+			args: args{file: brokenMain},
+			want: []*nvim.QuickfixError{
+				{FileName: brokenMain, LNum: 4, Col: 2, Text: `"go/ast" imported and not used`},
+				{FileName: brokenMain, LNum: 5, Col: 2, Text: `"go/parser" imported and not used`},
+				{FileName: brokenMain, LNum: 6, Col: 2, Text: `"go/token" imported and not used`},
+				{FileName: brokenMain, LNum: 7, Col: 2, Text: `"log" imported and not used`},
+				{FileName: brokenMain, LNum: 19, Col: 2, Text: "expected declaration, found fset"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := typeCheck(context.Background(), tt.args.file, tt.args.overlay)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("typeCheck(%v) error = %v, wantErr %v", filepath.Base(tt.args.file), err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("typeCheck(%v) = %v, want %v", filepath.Base(tt.args.file), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeCheckCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := typeCheck(ctx, astdumpMain, nil)
+	if err != context.Canceled {
+		t.Errorf("typeCheck(%v) error = %v, want %v", filepath.Base(astdumpMain), err, context.Canceled)
+	}
+	if got != nil {
+		t.Errorf("typeCheck(%v) = %v, want nil", filepath.Base(astdumpMain), got)
+	}
+}
+
+func TestTypeCheckModule(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+
+	bar := filepath.Join(testCwd, "..", "pathutil", "testdata", "mod", "foo", "bar", "bar.go")
+	type args struct {
+		overlay map[string][]byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*nvim.QuickfixError
+	}{
+		{
+			name: "import the module package",
+			args: args{overlay: map[string][]byte{bar: []byte("package bar\n\nimport \"foo.org/foo\"\n\nfunc Bar() { foo.Foo() }\n")}},
+			want: nil,
+		},
+		{
+			name: "type error of the module package",
+			args: args{overlay: map[string][]byte{bar: []byte("package bar\n\nimport \"foo.org/foo\"\n\nfunc Bar() { foo.Baz() }\n")}},
+			want: []*nvim.QuickfixError{
+				{FileName: bar, LNum: 5, Col: 18, Text: "undefined: foo.Baz"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := typeCheck(context.Background(), bar, tt.args.overlay)
+		if err != nil {
+			t.Errorf("%s: typeCheck(%v) error = %v", tt.name, filepath.Base(bar), err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: typeCheck(%v) = %+v, want %+v", tt.name, filepath.Base(bar), got, tt.want)
+		}
+	}
+}
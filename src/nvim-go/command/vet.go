@@ -6,6 +6,7 @@ package command
 
 import (
 	"bytes"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -46,7 +47,9 @@ func (c *Command) Vet(args []string, eval *CmdVetEval) interface{} {
 	defer nvimutil.Profile(time.Now(), "GoVet")
 
 	vetCmd := exec.Command("go", "tool", "vet")
+	vetCmd.Args = append(vetCmd.Args, buildTagsFlag()...)
 	vetCmd.Dir = eval.Cwd
+	vetCmd.Env = append(os.Environ(), c.ctx.Build.TargetEnv()...)
 
 	switch {
 	case len(args) > 0:
@@ -98,11 +98,29 @@ func Merge(cfg, cfg2 *Config) *Config {
 		if strings.EqualFold(strings.Join(cfg.Build.Flags, ""), strings.Join(cfg2.Build.Flags, "")) {
 			cfg.Build.Flags = cfg2.Build.Flags
 		}
+		if strings.Join(cfg.Build.Tags, " ") != strings.Join(cfg2.Build.Tags, " ") {
+			cfg.Build.Tags = cfg2.Build.Tags
+		}
+		if strings.Join(cfg.Build.Targets, " ") != strings.Join(cfg2.Build.Targets, " ") {
+			cfg.Build.Targets = cfg2.Build.Targets
+		}
+		if cfg.Build.AutosaveMode != cfg2.Build.AutosaveMode {
+			cfg.Build.AutosaveMode = cfg2.Build.AutosaveMode
+		}
 		if itob(cfg.Build.Force) != itob(cfg2.Build.Force) {
 			cfg.Build.Force = cfg2.Build.Force
 		}
 	}
 
+	if cfg2.Bench != nil {
+		if cfg.Bench.Count != cfg2.Bench.Count {
+			cfg.Bench.Count = cfg2.Bench.Count
+		}
+		if strings.Join(cfg.Bench.Flags, " ") != strings.Join(cfg2.Bench.Flags, " ") {
+			cfg.Bench.Flags = cfg2.Bench.Flags
+		}
+	}
+
 	if cfg2.Cover != nil {
 		if strings.EqualFold(strings.Join(cfg.Cover.Flags, ""), strings.Join(cfg2.Cover.Flags, "")) {
 			cfg.Cover.Flags = cfg2.Cover.Flags
@@ -110,6 +128,21 @@ func Merge(cfg, cfg2 *Config) *Config {
 		if cfg.Cover.Mode != cfg2.Cover.Mode {
 			cfg.Cover.Mode = cfg2.Cover.Mode
 		}
+		if cfg.Cover.DiffBase != cfg2.Cover.DiffBase {
+			cfg.Cover.DiffBase = cfg2.Cover.DiffBase
+		}
+		if itob(cfg.Cover.Count) != itob(cfg2.Cover.Count) {
+			cfg.Cover.Count = cfg2.Cover.Count
+		}
+	}
+
+	if cfg2.Diagnostics != nil {
+		if itob(cfg.Diagnostics.Enable) != itob(cfg2.Diagnostics.Enable) {
+			cfg.Diagnostics.Enable = cfg2.Diagnostics.Enable
+		}
+		if cfg.Diagnostics.Delay != cfg2.Diagnostics.Delay {
+			cfg.Diagnostics.Delay = cfg2.Diagnostics.Delay
+		}
 	}
 
 	if cfg2.Fmt != nil {
@@ -146,6 +179,24 @@ func Merge(cfg, cfg2 *Config) *Config {
 		if itob(cfg.Guru.Reflection) != itob(cfg2.Guru.Reflection) {
 			cfg.Guru.Reflection = cfg2.Guru.Reflection
 		}
+		if itob(cfg.Guru.SameIdsAuto) != itob(cfg2.Guru.SameIdsAuto) {
+			cfg.Guru.SameIdsAuto = cfg2.Guru.SameIdsAuto
+		}
+		if itob(cfg.Guru.Cache) != itob(cfg2.Guru.Cache) {
+			cfg.Guru.Cache = cfg2.Guru.Cache
+		}
+		if itob(cfg.Guru.InfoAuto) != itob(cfg2.Guru.InfoAuto) {
+			cfg.Guru.InfoAuto = cfg2.Guru.InfoAuto
+		}
+		if cfg.Guru.CallgraphAlgo != cfg2.Guru.CallgraphAlgo {
+			cfg.Guru.CallgraphAlgo = cfg2.Guru.CallgraphAlgo
+		}
+		if cfg.Guru.CallgraphDepth != cfg2.Guru.CallgraphDepth {
+			cfg.Guru.CallgraphDepth = cfg2.Guru.CallgraphDepth
+		}
+		if cfg.Guru.CallgraphDot != cfg2.Guru.CallgraphDot {
+			cfg.Guru.CallgraphDot = cfg2.Guru.CallgraphDot
+		}
 	}
 
 	if cfg2.Iferr != nil {
@@ -227,6 +278,9 @@ func Merge(cfg, cfg2 *Config) *Config {
 		if strings.EqualFold(strings.Join(cfg.Test.Flags, ""), strings.Join(cfg2.Test.Flags, "")) {
 			cfg.Test.Flags = cfg2.Test.Flags
 		}
+		if itob(cfg.Test.JSON) != itob(cfg2.Test.JSON) {
+			cfg.Test.JSON = cfg2.Test.JSON
+		}
 	}
 
 	if cfg2.Debug != nil {
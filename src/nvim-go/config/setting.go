@@ -14,16 +14,18 @@ import (
 type Config struct {
 	Global *Global
 
-	Build    *build
-	Cover    *cover
-	Fmt      *fmt
-	Generate *generate
-	Guru     *guru
-	Iferr    *iferr
-	Lint     *lint
-	Rename   *rename
-	Terminal *terminal
-	Test     *test
+	Build       *build
+	Bench       *bench
+	Cover       *cover
+	Diagnostics *diagnostics
+	Fmt         *fmt
+	Generate    *generate
+	Guru        *guru
+	Iferr       *iferr
+	Lint        *lint
+	Rename      *rename
+	Terminal    *terminal
+	Test        *test
 
 	Debug *debug
 }
@@ -37,16 +39,33 @@ type Global struct {
 
 // build GoBuild command config variable.
 type build struct {
-	Appengine int64    `eval:"get(g:, 'go#build#appengine', 0)"`
-	Autosave  int64    `eval:"get(g:, 'go#build#autosave', 0)"`
-	Force     int64    `eval:"get(g:, 'go#build#force', 0)"`
-	Flags     []string `eval:"get(g:, 'go#build#flags', [])"`
-	IsNotGb   int64    `eval:"get(g:, 'go#build#is_not_gb', 0)"`
+	Appengine    int64    `eval:"get(g:, 'go#build#appengine', 0)"`
+	Autosave     int64    `eval:"get(g:, 'go#build#autosave', 0)"`
+	AutosaveMode string   `eval:"get(g:, 'go#build#autosave#mode', 'build')"`
+	Force        int64    `eval:"get(g:, 'go#build#force', 0)"`
+	Flags        []string `eval:"get(g:, 'go#build#flags', [])"`
+	Tags         []string `eval:"get(g:, 'go#build#tags', [])"`
+	Targets      []string `eval:"get(g:, 'go#build#targets', [])"`
+	IsNotGb      int64    `eval:"get(g:, 'go#build#is_not_gb', 0)"`
+}
+
+// bench represents a GoBench command config variable.
+type bench struct {
+	Count int64    `eval:"get(g:, 'go#bench#count', 5)"`
+	Flags []string `eval:"get(g:, 'go#bench#flags', [])"`
 }
 
 type cover struct {
-	Flags []string `eval:"get(g:, 'go#cover#flags', [])"`
-	Mode  string   `eval:"get(g:, 'go#cover#mode', '')"`
+	Flags    []string `eval:"get(g:, 'go#cover#flags', [])"`
+	Mode     string   `eval:"get(g:, 'go#cover#mode', '')"`
+	DiffBase string   `eval:"get(g:, 'go#cover#diff_base', 'HEAD')"`
+	Count    int64    `eval:"get(g:, 'go#cover#count', 0)"`
+}
+
+// diagnostics represents a live diagnostics config variable.
+type diagnostics struct {
+	Enable int64 `eval:"get(g:, 'go#diagnostics#enable', 0)"`
+	Delay  int64 `eval:"get(g:, 'go#diagnostics#delay', 500)"`
 }
 
 // fmt represents a GoFmt command config variable.
@@ -65,9 +84,16 @@ type generate struct {
 
 // guru represents a GoGuru command config variable.
 type guru struct {
-	Reflection int64            `eval:"get(g:, 'go#guru#reflection', 0)"`
-	KeepCursor map[string]int64 `eval:"get(g:, 'go#guru#keep_cursor', {'callees':0,'callers':0,'callstack':0,'definition':0,'describe':0,'freevars':0,'implements':0,'peers':0,'pointsto':0,'referrers':0,'whicherrs':0})"`
-	JumpFirst  int64            `eval:"get(g:, 'go#guru#jump_first', 0)"`
+	Reflection  int64            `eval:"get(g:, 'go#guru#reflection', 0)"`
+	KeepCursor  map[string]int64 `eval:"get(g:, 'go#guru#keep_cursor', {'callees':0,'callers':0,'callstack':0,'definition':0,'describe':0,'freevars':0,'implements':0,'peers':0,'pointsto':0,'referrers':0,'whicherrs':0})"`
+	JumpFirst   int64            `eval:"get(g:, 'go#guru#jump_first', 0)"`
+	SameIdsAuto int64            `eval:"get(g:, 'go#guru#sameids#auto', 0)"`
+	Cache       int64            `eval:"get(g:, 'go#guru#cache', 1)"`
+	InfoAuto    int64            `eval:"get(g:, 'go#guru#info#auto', 0)"`
+
+	CallgraphAlgo  string `eval:"get(g:, 'go#guru#callgraph#algo', 'static')"`
+	CallgraphDepth int64  `eval:"get(g:, 'go#guru#callgraph#depth', 3)"`
+	CallgraphDot   string `eval:"get(g:, 'go#guru#callgraph#dot', 'callgraph.dot')"`
 }
 
 // iferr represents a GoIferr command config variable.
@@ -110,6 +136,7 @@ type test struct {
 	AllPackage int64    `eval:"get(g:, 'go#test#all_package', 0)"`
 	Autosave   int64    `eval:"get(g:, 'go#test#autosave', 0)"`
 	Flags      []string `eval:"get(g:, 'go#test#flags', [])"`
+	JSON       int64    `eval:"get(g:, 'go#test#json', 0)"`
 }
 
 // Debug represents a debug of nvim-go config variable.
@@ -130,18 +157,40 @@ var (
 	BuildAppengine bool
 	// BuildAutosave call the GoBuild command automatically at during the BufWritePost.
 	BuildAutosave bool
+	// BuildAutosaveMode mode of the BuildAutosave. "build" spawns the compile tool,
+	// "typecheck" type-checks the package in-process with go/types.
+	BuildAutosaveMode string
 	// BuildForce builds the binary instead of fake(use ioutil.TempFiile) build.
 	BuildForce bool
 	// BuildFlags flag of compile tools build command.
 	BuildFlags []string
+	// BuildTags build tags of the go/build.Context and the go tools -tags flag.
+	BuildTags []string
+	// BuildTargets "GOOS/GOARCH" target platforms of the GoBuildMatrix command.
+	BuildTargets []string
 
 	// BuildIsNotGb workaround for not ues gb compiler.
 	BuildIsNotGb bool
 
+	// BenchCount number of times to run each benchmark, for the statistical comparison.
+	// The value less than 1 is treated as 1.
+	BenchCount int64
+	// BenchFlags flags for bench command.
+	BenchFlags []string
+
 	// CoverFlags flags for cover command.
 	CoverFlags []string
 	// CoverMode mode of cover command.
 	CoverMode string
+	// CoverDiffBase git ref of the base of GoCover diff coverage mode.
+	CoverDiffBase string
+	// CoverCount shows the execution count as the virtual text and the heat-map highlights in count or atomic mode.
+	CoverCount bool
+
+	// DiagnosticsEnable type-checks the buffer contents on TextChanged, TextChangedI and InsertLeave.
+	DiagnosticsEnable bool
+	// DiagnosticsDelay debounce delay of the live diagnostics in milliseconds.
+	DiagnosticsDelay int64
 
 	// FmtAutosave call the GoFmt command automatically at during the BufWritePre.
 	FmtAutosave bool
@@ -163,6 +212,20 @@ var (
 	GuruKeepCursor map[string]int64
 	// GuruJumpFirst jump the first error position on GoGuru commands.
 	GuruJumpFirst bool
+	// GuruSameIdsAuto highlights the same identifiers on CursorHold, and clears on CursorMoved.
+	GuruSameIdsAuto bool
+	// GuruCache keeps the loaded analysis scope program in memory, and reuses it on GoGuru commands.
+	// The cache is not per package, the whole scope program is reloaded in the background
+	// when any its package file is saved.
+	GuruCache bool
+	// GuruInfoAuto shows the identifier info in the floating window on CursorHold.
+	GuruInfoAuto bool
+	// GuruCallgraphAlgo default call graph algorithm of GoCallgraph. "static", "cha" or "pointer".
+	GuruCallgraphAlgo string
+	// GuruCallgraphDepth default depth limit of GoCallgraph from the focus functions. 0 is unlimited.
+	GuruCallgraphDepth int64
+	// GuruCallgraphDot output path of the GoCallgraph DOT file, relative to the current directory.
+	GuruCallgraphDot string
 
 	// IferrAutosave call the GoIferr command automatically at during the BufWritePre.
 	IferrAutosave bool
@@ -212,6 +275,8 @@ var (
 	TestAll bool
 	// TestFlags test command default flags.
 	TestFlags []string
+	// TestJSON parses the go test -json results to the errorlist and signs instead of the terminal.
+	TestJSON bool
 
 	// DebugEnable Enable debugging.
 	DebugEnable bool
@@ -229,13 +294,26 @@ func Get(v *nvim.Nvim, cfg *Config) {
 	// Build
 	BuildAppengine = itob(cfg.Build.Appengine)
 	BuildAutosave = itob(cfg.Build.Autosave)
+	BuildAutosaveMode = cfg.Build.AutosaveMode
 	BuildForce = itob(cfg.Build.Force)
 	BuildFlags = cfg.Build.Flags
+	BuildTags = cfg.Build.Tags
+	BuildTargets = cfg.Build.Targets
 	BuildIsNotGb = itob(cfg.Build.IsNotGb)
 
+	// Bench
+	BenchCount = cfg.Bench.Count
+	BenchFlags = cfg.Bench.Flags
+
 	// Cover
 	CoverFlags = cfg.Cover.Flags
 	CoverMode = cfg.Cover.Mode
+	CoverDiffBase = cfg.Cover.DiffBase
+	CoverCount = itob(cfg.Cover.Count)
+
+	// Diagnostics
+	DiagnosticsEnable = itob(cfg.Diagnostics.Enable)
+	DiagnosticsDelay = cfg.Diagnostics.Delay
 
 	// Fmt
 	FmtAutosave = itob(cfg.Fmt.Autosave)
@@ -251,6 +329,12 @@ func Get(v *nvim.Nvim, cfg *Config) {
 	GuruReflection = itob(cfg.Guru.Reflection)
 	GuruKeepCursor = cfg.Guru.KeepCursor
 	GuruJumpFirst = itob(cfg.Guru.JumpFirst)
+	GuruSameIdsAuto = itob(cfg.Guru.SameIdsAuto)
+	GuruCache = itob(cfg.Guru.Cache)
+	GuruInfoAuto = itob(cfg.Guru.InfoAuto)
+	GuruCallgraphAlgo = cfg.Guru.CallgraphAlgo
+	GuruCallgraphDepth = cfg.Guru.CallgraphDepth
+	GuruCallgraphDot = cfg.Guru.CallgraphDot
 
 	// Iferr
 	IferrAutosave = itob(cfg.Iferr.Autosave)
@@ -283,6 +367,7 @@ func Get(v *nvim.Nvim, cfg *Config) {
 	TestAutosave = itob(cfg.Test.Autosave)
 	TestAll = itob(cfg.Test.AllPackage)
 	TestFlags = cfg.Test.Flags
+	TestJSON = itob(cfg.Test.JSON)
 
 	// Debug
 	DebugEnable = itob(cfg.Debug.Enable)
@@ -40,8 +40,65 @@ type Build struct {
 	// Tool name of build tool
 	Tool string
 	// ProjectRoot package directory full path in the case of go project,
-	// GB_PROJECT_DIR in the case of gb project.
+	// GB_PROJECT_DIR in the case of gb project,
+	// directory of go.mod file in the case of Go module project.
 	ProjectRoot string
+	// WorkspaceRoot directory of go.work file if the Go module project is
+	// the member of Go workspace.
+	WorkspaceRoot string
+	// Modules full path list of the Go workspace member modules.
+	Modules []string
+	// GOOS and GOARCH target platform set by the GoTarget command.
+	// Empty if the target is the host platform.
+	GOOS   string
+	GOARCH string
+}
+
+// Compiler returns the compile tool command name corresponding to the build tool.
+func (b *Build) Compiler() string {
+	if b.Tool == "gb" {
+		return "gb"
+	}
+	return "go"
+}
+
+// ModuleRoots returns the all of workspace member modules if the project is
+// the member of Go workspace, otherwise returns the ProjectRoot only.
+func (b *Build) ModuleRoots() []string {
+	if b.WorkspaceRoot != "" {
+		return b.Modules
+	}
+	return []string{b.ProjectRoot}
+}
+
+// Root returns the directory where the go tool resolves the module packages.
+// That is the workspace root if the project is the member of Go workspace,
+// otherwise returns the ProjectRoot.
+func (b *Build) Root() string {
+	if b.WorkspaceRoot != "" {
+		return b.WorkspaceRoot
+	}
+	return b.ProjectRoot
+}
+
+// workspace returns the Go workspace root and the member modules of that
+// workspace if the modRoot module is the member of Go workspace.
+func workspace(modRoot string) (string, []string) {
+	workRoot, ok := pathutil.IsWorkspace(modRoot)
+	if !ok {
+		return "", nil
+	}
+	modules, err := pathutil.WorkspaceModules(workRoot)
+	if err != nil {
+		return "", nil
+	}
+	for _, mod := range modules {
+		if mod == modRoot {
+			return workRoot, modules
+		}
+	}
+
+	return "", nil
 }
 
 // NewContext return the Context type with initialize Context.Errlist.
@@ -55,12 +112,23 @@ func NewContext() *Context {
 func buildContext(dir string, defaultContext build.Context) (string, string, build.Context) {
 	// copy context
 	buildContext := defaultContext
+	// reset the module root of the previous context
+	buildContext.Dir = ""
 
 	// Default is go context
 	tool := "go"
 	// Assign package directory full path from dir
 	projectRoot, _ := pathutil.PackagePath(dir)
 
+	// Check whether the dir is under the Go module.
+	// If ok, use the module root directory as the project root.
+	if modRoot, ok := pathutil.IsModule(filepath.Clean(dir)); ok {
+		tool = "mod"
+		projectRoot = modRoot
+		buildContext.Dir = modRoot
+		return tool, projectRoot, buildContext
+	}
+
 	// Check whether the dir is Gb directory structure.
 	// If ok, append gb root and vendor path to the goPath lists.
 	if gbpath, ok := pathutil.IsGb(filepath.Clean(dir)); ok {
@@ -72,7 +140,7 @@ func buildContext(dir string, defaultContext build.Context) (string, string, bui
 	return tool, projectRoot, buildContext
 }
 
-// SetContext sets the Tool, ProjectRoot, go/build.Default and $GOPATH to buildContext.
+// SetContext sets the Tool, ProjectRoot, Go workspace, go/build.Default and $GOPATH to buildContext.
 // This function initializes for functions that use go/build.Default.
 func (ctx *Context) SetContext(dir string) {
 	if dir != "" && ctx.prevDir != dir {
@@ -80,8 +148,13 @@ func (ctx *Context) SetContext(dir string) {
 		defer ctx.m.Unlock()
 
 		ctx.Build.Tool, ctx.Build.ProjectRoot, build.Default = buildContext(dir, build.Default)
-		if ctx.Build.Tool == "gb" {
+		ctx.Build.WorkspaceRoot, ctx.Build.Modules = "", nil
+		switch ctx.Build.Tool {
+		case "gb":
 			build.Default.JoinPath = ctx.Build.GbJoinPath
+		case "mod":
+			ctx.Build.WorkspaceRoot, ctx.Build.Modules = workspace(ctx.Build.ProjectRoot)
+			build.Default.Dir = ctx.Build.Root()
 		}
 		ctx.prevDir = dir
 
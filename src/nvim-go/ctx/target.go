@@ -0,0 +1,60 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ctx
+
+import (
+	"go/build"
+)
+
+// hostTarget holds the GOOS, GOARCH and CgoEnabled of the go/build.Default
+// before changed by SetTarget, for reset to the host platform.
+var hostTarget = struct {
+	goos, goarch string
+	cgoEnabled   bool
+}{build.Default.GOOS, build.Default.GOARCH, build.Default.CgoEnabled}
+
+// Target returns the current target GOOS and GOARCH of go/build.Default.
+func (ctx *Context) Target() (goos, goarch string) {
+	ctx.m.Lock()
+	defer ctx.m.Unlock()
+
+	return build.Default.GOOS, build.Default.GOARCH
+}
+
+// SetTarget sets the GOOS and GOARCH of go/build.Default and the TargetEnv
+// to the goos and goarch target platform.
+// Resets to the host platform if goos and goarch are empty.
+func (ctx *Context) SetTarget(goos, goarch string) {
+	ctx.m.Lock()
+	defer ctx.m.Unlock()
+
+	if goos == "" {
+		goos = hostTarget.goos
+	}
+	if goarch == "" {
+		goarch = hostTarget.goarch
+	}
+	ctx.Build.GOOS, ctx.Build.GOARCH = "", ""
+	if goos != hostTarget.goos || goarch != hostTarget.goarch {
+		ctx.Build.GOOS, ctx.Build.GOARCH = goos, goarch
+	}
+
+	build.Default.GOOS = goos
+	build.Default.GOARCH = goarch
+	// cgo is disabled by default when cross-compiling
+	build.Default.CgoEnabled = hostTarget.cgoEnabled && ctx.Build.GOOS == ""
+}
+
+// TargetEnv returns the GOOS and GOARCH environment variables of the target
+// platform for the go build, test and vet commands and the Neovim terminal.
+// The environment of the plugin process is not changed by SetTarget, so the
+// other child processes such as gofmt and git are not affected.
+// Returns nil if the target is the host platform.
+func (b *Build) TargetEnv() []string {
+	if b.GOOS == "" {
+		return nil
+	}
+	return []string{"GOOS=" + b.GOOS, "GOARCH=" + b.GOARCH}
+}
@@ -0,0 +1,333 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file provides support for generating the coverage HTML such as
+// "go tool cover -html=cover.out" without the go tool.
+// It is based on golang/go/src/cmd/cover/html.go, and adds the syntax
+// colouring of the source.
+
+package cover
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html/template"
+	"io"
+	"math"
+)
+
+// HTMLFile represents a source file of the coverage HTML.
+type HTMLFile struct {
+	// Name display name of the file. Usually the profile file name.
+	Name    string
+	Src     []byte
+	Profile *Profile
+}
+
+// WriteHTML writes the self-contained coverage HTML of files to w.
+func WriteHTML(w io.Writer, files []HTMLFile) error {
+	var d templateData
+	for _, f := range files {
+		var buf bytes.Buffer
+		if err := htmlGen(&buf, f.Src, f.Profile.Boundaries(f.Src), syntaxTokens(f.Src)); err != nil {
+			return err
+		}
+		d.Files = append(d.Files, &templateFile{
+			Name:     f.Name,
+			Body:     template.HTML(buf.String()),
+			Coverage: percentCovered(f.Profile),
+		})
+		if f.Profile.Mode == "set" {
+			d.Set = true
+		}
+	}
+
+	return htmlTemplate.Execute(w, d)
+}
+
+// percentCovered returns, as a percentage, the fraction of the statements in
+// the profile covered by the test run.
+// In effect, it reports the coverage of a given source file.
+func percentCovered(p *Profile) float64 {
+	var total, covered int64
+	for _, b := range p.Blocks {
+		total += int64(b.NumStmt)
+		if b.Count > 0 {
+			covered += int64(b.NumStmt)
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// syntaxToken represents the syntax colouring range of the source.
+type syntaxToken struct {
+	start, end int
+	class      string
+}
+
+// syntaxTokens scans the Go source, and returns the keyword, literal and comment tokens.
+func syntaxTokens(src []byte) []syntaxToken {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var tokens []syntaxToken
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		var class string
+		switch {
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok.IsKeyword():
+			class = "kw"
+			lit = tok.String()
+		default:
+			continue
+		}
+
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if end > len(src) {
+			end = len(src)
+		}
+		tokens = append(tokens, syntaxToken{start: start, end: end, class: class})
+	}
+
+	return tokens
+}
+
+// htmlGen generates an HTML coverage report with the provided filename,
+// source code, and tokens, and writes it to the given Writer.
+// The syntax token span is closed and reopened around the coverage boundary,
+// for keep the spans nested.
+func htmlGen(w io.Writer, src []byte, boundaries []Boundary, tokens []syntaxToken) error {
+	dst := bufio.NewWriter(w)
+
+	var tok *syntaxToken
+	for i := range src {
+		if tok != nil && tok.end == i {
+			dst.WriteString("</span>")
+			tok = nil
+		}
+
+		if len(boundaries) > 0 && boundaries[0].Offset == i {
+			if tok != nil {
+				dst.WriteString("</span>")
+			}
+			for len(boundaries) > 0 && boundaries[0].Offset == i {
+				writeBoundary(dst, boundaries[0])
+				boundaries = boundaries[1:]
+			}
+			if tok != nil {
+				fmt.Fprintf(dst, `<span class="%s">`, tok.class)
+			}
+		}
+
+		for tok == nil && len(tokens) > 0 && tokens[0].start <= i {
+			if tokens[0].start == i && tokens[0].end > i {
+				tok = &tokens[0]
+				fmt.Fprintf(dst, `<span class="%s">`, tok.class)
+			}
+			tokens = tokens[1:]
+		}
+
+		switch b := src[i]; b {
+		case '>':
+			dst.WriteString("&gt;")
+		case '<':
+			dst.WriteString("&lt;")
+		case '&':
+			dst.WriteString("&amp;")
+		case '\t':
+			dst.WriteString("        ")
+		default:
+			dst.WriteByte(b)
+		}
+	}
+	if tok != nil {
+		dst.WriteString("</span>")
+	}
+	for _, b := range boundaries {
+		writeBoundary(dst, b)
+	}
+
+	return dst.Flush()
+}
+
+// writeBoundary writes the span tag of the coverage boundary with the hit count title.
+func writeBoundary(w *bufio.Writer, b Boundary) {
+	if !b.Start {
+		w.WriteString("</span>")
+		return
+	}
+
+	n := 0
+	if b.Count > 0 {
+		n = int(math.Floor(b.Norm*9)) + 1
+	}
+	fmt.Fprintf(w, `<span class="cov%v" title="%v hits">`, n, b.Count)
+}
+
+// rgb returns an rgb value for the specified coverage value
+// between 0 (no coverage) and 10 (max coverage).
+func rgb(n int) string {
+	if n == 0 {
+		return "rgb(192, 0, 0)" // Red
+	}
+	// Gradient from gray to green.
+	r := 128 - 12*(n-1)
+	g := 128 + 12*(n-1)
+	b := 128 + 3*(n-1)
+	return fmt.Sprintf("rgb(%v, %v, %v)", r, g, b)
+}
+
+// colors generates the CSS rules for coverage colors.
+func colors() template.CSS {
+	var buf bytes.Buffer
+	for i := 0; i < 11; i++ {
+		fmt.Fprintf(&buf, ".cov%v { color: %v }\n", i, rgb(i))
+	}
+	return template.CSS(buf.String())
+}
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"colors": colors,
+}).Parse(tmplHTML))
+
+type templateData struct {
+	Files []*templateFile
+	Set   bool
+}
+
+type templateFile struct {
+	Name     string
+	Body     template.HTML
+	Coverage float64
+}
+
+const tmplHTML = `
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<title>nvim-go coverage</title>
+		<style>
+			body {
+				background: black;
+				color: rgb(80, 80, 80);
+			}
+			body, pre, #legend span {
+				font-family: Menlo, monospace;
+				font-weight: bold;
+			}
+			#topbar {
+				background: black;
+				position: fixed;
+				top: 0; left: 0; right: 0;
+				height: 42px;
+				border-bottom: 1px solid rgb(80, 80, 80);
+			}
+			#content {
+				margin-top: 50px;
+			}
+			#nav, #legend {
+				float: left;
+				margin-left: 10px;
+			}
+			#legend {
+				margin-top: 12px;
+			}
+			#nav {
+				margin-top: 10px;
+			}
+			#legend span {
+				margin: 0 5px;
+			}
+			.kw { font-style: italic; text-decoration: underline }
+			.str { font-style: italic }
+			.num { text-decoration: underline }
+			.com { font-weight: normal; opacity: 0.6 }
+			{{colors}}
+		</style>
+	</head>
+	<body>
+		<div id="topbar">
+			<div id="nav">
+				<select id="files">
+				{{range $i, $f := .Files}}
+				<option value="file{{$i}}">{{$f.Name}} ({{printf "%.1f" $f.Coverage}}%)</option>
+				{{end}}
+				</select>
+			</div>
+			<div id="legend">
+				<span>not tracked</span>
+			{{if .Set}}
+				<span class="cov0">not covered</span>
+				<span class="cov8">covered</span>
+			{{else}}
+				<span class="cov0">no coverage</span>
+				<span class="cov1">low coverage</span>
+				<span class="cov2">*</span>
+				<span class="cov3">*</span>
+				<span class="cov4">*</span>
+				<span class="cov5">*</span>
+				<span class="cov6">*</span>
+				<span class="cov7">*</span>
+				<span class="cov8">*</span>
+				<span class="cov9">*</span>
+				<span class="cov10">high coverage</span>
+			{{end}}
+			</div>
+		</div>
+		<div id="content">
+		{{range $i, $f := .Files}}
+		<pre class="file" id="file{{$i}}" style="display: none">{{$f.Body}}</pre>
+		{{end}}
+		</div>
+	</body>
+	<script>
+	(function() {
+		var files = document.getElementById('files');
+		var visible;
+		files.addEventListener('change', onChange, false);
+		function select(part) {
+			if (visible)
+				visible.style.display = 'none';
+			visible = document.getElementById(part);
+			if (!visible)
+				return;
+			files.value = part;
+			visible.style.display = 'block';
+			location.hash = part;
+		}
+		function onChange() {
+			select(files.value);
+			window.scrollTo(0, 0);
+		}
+		if (location.hash != "") {
+			select(location.hash.substr(1));
+		}
+		if (!visible) {
+			select("file0");
+		}
+	})();
+	</script>
+</html>
+`
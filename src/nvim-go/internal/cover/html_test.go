@@ -0,0 +1,48 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHTMLGen(t *testing.T) {
+	src := []byte("func f() {\n\ts := \"a<b\" // c\n}\n")
+	prof := &Profile{
+		Mode: "set",
+		Blocks: []ProfileBlock{
+			{StartLine: 1, StartCol: 10, EndLine: 3, EndCol: 2, NumStmt: 1, Count: 1},
+		},
+	}
+
+	tests := []struct {
+		name string
+		src  []byte
+		want string
+	}{
+		{
+			name: "syntax and boundaries",
+			src:  src,
+			want: `<span class="kw">func</span> f()<span class="cov8" title="1 hits"> {
+        s := <span class="str">"a&lt;b"</span> <span class="com">// c</span>
+}</span>
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := htmlGen(&buf, tt.src, prof.Boundaries(tt.src), syntaxTokens(tt.src)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("htmlGen() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
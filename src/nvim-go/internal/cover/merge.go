@@ -0,0 +1,85 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// MergeProfiles merges the profiles of the multiple go test runs into a
+// Profile for each source file.
+// The counts of the same block are summed, or ORed in the "set" mode same as
+// ParseProfiles. All profiles must have the same mode.
+func MergeProfiles(profiles ...[]*Profile) ([]*Profile, error) {
+	files := make(map[string]*Profile)
+	mode := ""
+	for _, profs := range profiles {
+		for _, p := range profs {
+			if mode == "" {
+				mode = p.Mode
+			}
+			if p.Mode != mode {
+				return nil, fmt.Errorf("inconsistent mode: %s and %s", mode, p.Mode)
+			}
+
+			merged, ok := files[p.FileName]
+			if !ok {
+				merged = &Profile{FileName: p.FileName, Mode: p.Mode}
+				files[p.FileName] = merged
+			}
+			merged.Blocks = append(merged.Blocks, p.Blocks...)
+		}
+	}
+
+	merged := make([]*Profile, 0, len(files))
+	for _, p := range files {
+		sort.Stable(blocksByStart(p.Blocks))
+		j := 0
+		for i, b := range p.Blocks {
+			if i > 0 {
+				last := &p.Blocks[j-1]
+				if b.StartLine == last.StartLine && b.StartCol == last.StartCol &&
+					b.EndLine == last.EndLine && b.EndCol == last.EndCol {
+					if b.NumStmt != last.NumStmt {
+						return nil, fmt.Errorf("%s: inconsistent NumStmt: changed from %d to %d", p.FileName, last.NumStmt, b.NumStmt)
+					}
+					if mode == "set" {
+						last.Count |= b.Count
+					} else {
+						last.Count += b.Count
+					}
+					continue
+				}
+			}
+			p.Blocks[j] = b
+			j++
+		}
+		p.Blocks = p.Blocks[:j]
+		merged = append(merged, p)
+	}
+	sort.Sort(byFileName(merged))
+
+	return merged, nil
+}
+
+// WriteProfiles writes the profiles to w in the "go test -coverprofile" format.
+func WriteProfiles(w io.Writer, profiles []*Profile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", profiles[0].Mode)
+	for _, p := range profiles {
+		for _, b := range p.Blocks {
+			fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n", p.FileName, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cover
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMergeProfiles(t *testing.T) {
+	type args struct {
+		profiles [][]*Profile
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*Profile
+		wantErr bool
+	}{
+		{
+			name: "count",
+			args: args{profiles: [][]*Profile{
+				{
+					{FileName: "foo/b.go", Mode: "count", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1}}},
+					{FileName: "foo/a.go", Mode: "count", Blocks: []ProfileBlock{
+						{StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 2, NumStmt: 2, Count: 0},
+						{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 2},
+					}},
+				},
+				{
+					{FileName: "foo/a.go", Mode: "count", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 3}}},
+				},
+			}},
+			want: []*Profile{
+				{FileName: "foo/a.go", Mode: "count", Blocks: []ProfileBlock{
+					{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 5},
+					{StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 2, NumStmt: 2, Count: 0},
+				}},
+				{FileName: "foo/b.go", Mode: "count", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1}}},
+			},
+		},
+		{
+			name: "set",
+			args: args{profiles: [][]*Profile{
+				{{FileName: "foo/a.go", Mode: "set", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1}}}},
+				{{FileName: "foo/a.go", Mode: "set", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1}}}},
+			}},
+			want: []*Profile{
+				{FileName: "foo/a.go", Mode: "set", Blocks: []ProfileBlock{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1}}},
+			},
+		},
+		{
+			name: "inconsistent mode",
+			args: args{profiles: [][]*Profile{
+				{{FileName: "foo/a.go", Mode: "set"}},
+				{{FileName: "foo/a.go", Mode: "count"}},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := MergeProfiles(tt.args.profiles...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MergeProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeProfiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteProfiles(t *testing.T) {
+	profiles := []*Profile{
+		{FileName: "foo/a.go", Mode: "atomic", Blocks: []ProfileBlock{
+			{StartLine: 1, StartCol: 13, EndLine: 3, EndCol: 2, NumStmt: 2, Count: 7},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProfiles(&buf, profiles); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "mode: atomic\nfoo/a.go:1.13,3.2 2 7\n"; got != want {
+		t.Errorf("WriteProfiles() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/pointer"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// maxCachedPrograms is the number of the cached scope programs, such as the
+// different build tags or target platforms. The least recently used program is discarded.
+const maxCachedPrograms = 4
+
+// Cache keeps the loaded and type-checked program of the analysis scope, and
+// the SSA program and the pointer analysis call graph built from it, across
+// the queries.
+//
+// The invalidation is not per package. The loader does not support reloading
+// a part of the program, so the change of any one package file discards the
+// whole scope program, and the whole scope is reloaded in the background by
+// Reload or by the next query. The other programs, such as the different scope,
+// are still reused.
+//
+// The queries on the modified buffer load the program with the overlay and
+// do not use the cache, so the buffer edits do not invalidate the cached
+// program of the files on disk.
+type Cache struct {
+	mu       sync.Mutex
+	programs map[string]*scopeProgram
+	// reloads the in-flight background reloads of the cache key.
+	reloads map[string]*reload
+}
+
+// reload represents the in-flight background reload of the scope program.
+type reload struct {
+	// done closed when the reload is finished.
+	done chan struct{}
+	// again reports whether the files are changed again during the reload.
+	again bool
+}
+
+// NewCache returns the new empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		programs: make(map[string]*scopeProgram),
+		reloads:  make(map[string]*reload),
+	}
+}
+
+// Invalidate discards the cached programs that have the package of filename.
+func (c *Cache) Invalidate(filename string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidate(filename)
+}
+
+// Reload discards the cached programs that have the package of filename, such
+// as the saved file, and reloads their whole scope programs in the background.
+// The query of the reloading scope waits for the reload instead of loading it again.
+func (c *Cache) Reload(filename string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, q := range c.invalidate(filename) {
+		if r, ok := c.reloads[key]; ok {
+			r.again = true
+			continue
+		}
+		r := &reload{done: make(chan struct{})}
+		c.reloads[key] = r
+		go c.reload(key, q, r)
+	}
+}
+
+// invalidate discards the cached programs that have the package of filename,
+// and returns the queries of the discarded programs by the cache key.
+// The caller must hold c.mu.
+func (c *Cache) invalidate(filename string) map[string]*Query {
+	dir := filepath.Dir(filename)
+
+	queries := make(map[string]*Query)
+	for key, sp := range c.programs {
+		if sp.dirs[dir] {
+			queries[key] = sp.query
+			delete(c.programs, key)
+		}
+	}
+	return queries
+}
+
+// reload loads the program of q and caches it as key, loads again if the
+// files are changed during the load.
+func (c *Cache) reload(key string, q *Query, r *reload) {
+	defer close(r.done)
+
+	for {
+		sp, err := loadScopeProgram(q)
+
+		c.mu.Lock()
+		if r.again {
+			r.again = false
+			c.mu.Unlock()
+			continue
+		}
+		delete(c.reloads, key)
+		if _, ok := c.programs[key]; !ok && err == nil {
+			sp.used = time.Now()
+			c.programs[key] = sp
+			c.evict()
+		}
+		c.mu.Unlock()
+		return
+	}
+}
+
+// Clear discards the all cached programs.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.programs = make(map[string]*scopeProgram)
+}
+
+// get returns the locked program of the q.Scope from the cache, or loads
+// and caches it if not cached or the files are modified since loaded.
+// Waits for the background reload of the q.Scope if in-flight.
+func (c *Cache) get(q *Query) (*scopeProgram, error) {
+	key := cacheKey(q)
+
+	c.mu.Lock()
+	if r, ok := c.reloads[key]; ok {
+		c.mu.Unlock()
+		<-r.done
+		c.mu.Lock()
+	}
+	sp, ok := c.programs[key]
+	if ok {
+		sp.used = time.Now()
+	}
+	c.mu.Unlock()
+
+	if !ok || !sp.isValid() {
+		var err error
+		sp, err = loadScopeProgram(q)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		sp.used = time.Now()
+		c.programs[key] = sp
+		c.evict()
+		c.mu.Unlock()
+	}
+
+	sp.mu.Lock()
+
+	return sp, nil
+}
+
+// evict discards the least recently used programs over the maxCachedPrograms.
+// The caller must hold c.mu.
+func (c *Cache) evict() {
+	for len(c.programs) > maxCachedPrograms {
+		var (
+			oldest string
+			used   time.Time
+		)
+		for key, sp := range c.programs {
+			if oldest == "" || sp.used.Before(used) {
+				oldest, used = key, sp.used
+			}
+		}
+		delete(c.programs, oldest)
+	}
+}
+
+// cacheKey returns the cache key of the q.Scope program with the build context.
+func cacheKey(q *Query) string {
+	ctxt := q.Build
+	return strings.Join([]string{
+		ctxt.GOOS,
+		ctxt.GOARCH,
+		ctxt.GOROOT,
+		ctxt.GOPATH,
+		ctxt.Dir,
+		strings.Join(ctxt.BuildTags, ","),
+		strconv.FormatBool(ctxt.CgoEnabled),
+		strings.Join(q.Scope, " "),
+	}, "\x00")
+}
+
+// scopeProgram represents the loaded program of the analysis scope.
+// The queries on the program are serialized by mu.
+type scopeProgram struct {
+	mu sync.Mutex
+	// used last used time for the eviction. guarded by the Cache.mu.
+	used time.Time
+	// query the query of the scope for the reload.
+	query *Query
+
+	lprog      *loader.Program
+	ssa        map[ssa.BuilderMode]*ssa.Program
+	mains      map[*ssa.Program][]*ssa.Package
+	callgraphs map[callgraphKey]*callgraph.Graph
+
+	// initial import paths of the scope packages, that are loaded with the tests.
+	initial map[string]bool
+	// dirs directories of the loaded packages.
+	dirs map[string]bool
+	// files modification time of the loaded files, except the GOROOT files.
+	files map[string]time.Time
+}
+
+// callgraphKey is the key of the cached pointer analysis call graph.
+type callgraphKey struct {
+	prog       *ssa.Program
+	reflection bool
+}
+
+// loadScopeProgram loads the program of the q.Scope.
+func loadScopeProgram(q *Query) (*scopeProgram, error) {
+	lconf := loader.Config{Build: q.Build}
+	if err := setPTAScope(&lconf, q.Scope); err != nil {
+		return nil, err
+	}
+
+	// Load/parse/type-check the program.
+	lprog, err := loadWithSoftErrors(&lconf)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &scopeProgram{
+		query:      &Query{Build: q.Build, Scope: q.Scope},
+		lprog:      lprog,
+		ssa:        make(map[ssa.BuilderMode]*ssa.Program),
+		mains:      make(map[*ssa.Program][]*ssa.Package),
+		callgraphs: make(map[callgraphKey]*callgraph.Graph),
+		initial:    make(map[string]bool),
+		dirs:       make(map[string]bool),
+		files:      make(map[string]time.Time),
+	}
+	for _, info := range lprog.InitialPackages() {
+		sp.initial[info.Pkg.Path()] = true
+	}
+	for _, info := range lprog.AllPackages {
+		for _, f := range info.Files {
+			filename := lprog.Fset.File(f.Pos()).Name()
+			sp.dirs[filepath.Dir(filename)] = true
+			if q.Build.GOROOT != "" && strings.HasPrefix(filename, q.Build.GOROOT+string(filepath.Separator)) {
+				continue
+			}
+			if fi, err := os.Stat(filename); err == nil {
+				sp.files[filename] = fi.ModTime()
+			}
+		}
+	}
+
+	return sp, nil
+}
+
+// isValid reports whether the loaded files are not modified since loaded,
+// such as the changes outside of Neovim.
+func (sp *scopeProgram) isValid() bool {
+	for filename, mtime := range sp.files {
+		fi, err := os.Stat(filename)
+		if err != nil || !fi.ModTime().Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// unlock unlocks the program locked by the Cache.get or Query.scopeProgram.
+func (sp *scopeProgram) unlock() { sp.mu.Unlock() }
+
+// ssaProgram returns the SSA program of the mode, creates it if not created yet.
+func (sp *scopeProgram) ssaProgram(mode ssa.BuilderMode) *ssa.Program {
+	prog, ok := sp.ssa[mode]
+	if !ok {
+		prog = ssautil.CreateProgram(sp.lprog, mode)
+		sp.ssa[mode] = prog
+	}
+	return prog
+}
+
+// setupPTA returns the pointer.Config of the scope of prog such as setupPTA,
+// reuses the main packages of prog because the synthesized test main
+// package cannot be created twice.
+func (sp *scopeProgram) setupPTA(prog *ssa.Program, ptaLog io.Writer, reflection bool) (*pointer.Config, error) {
+	mains, ok := sp.mains[prog]
+	if !ok {
+		conf, err := setupPTA(prog, sp.lprog, ptaLog, reflection)
+		if err != nil {
+			return nil, err
+		}
+		sp.mains[prog] = conf.Mains
+		return conf, nil
+	}
+
+	return &pointer.Config{
+		Log:        ptaLog,
+		Reflection: reflection,
+		Mains:      mains,
+	}, nil
+}
+
+// hasPackages reports whether the all paths packages are loaded with the
+// tests as the scope packages.
+func (sp *scopeProgram) hasPackages(paths map[string]bool) bool {
+	for path := range paths {
+		if !sp.initial[path] {
+			return false
+		}
+	}
+	return true
+}
+
+// callGraph returns the call graph of the pointer analysis of conf that
+// has no queries, runs the analysis if not analysed yet.
+// The synthetic nodes are deleted from the returned call graph.
+func (sp *scopeProgram) callGraph(conf *pointer.Config) *callgraph.Graph {
+	key := callgraphKey{prog: conf.Mains[0].Prog, reflection: conf.Reflection}
+	cg, ok := sp.callgraphs[key]
+	if !ok {
+		conf.BuildCallGraph = true
+		cg = ptrAnalysis(conf).CallGraph
+		cg.DeleteSyntheticNodes()
+		sp.callgraphs[key] = cg
+	}
+	return cg
+}
+
+// scopeProgram returns the locked program of the q.Scope. Uses the q.Cache if
+// set and the build context does not have the overlay files.
+// The caller must unlock the program.
+func (q *Query) scopeProgram() (*scopeProgram, error) {
+	if q.Cache != nil && q.Build.OpenFile == nil {
+		return q.Cache.get(q)
+	}
+
+	sp, err := loadScopeProgram(q)
+	if err != nil {
+		return nil, err
+	}
+	sp.mu.Lock()
+
+	return sp, nil
+}
+
+// cachedProgram returns the locked program of the q.Scope from the q.Cache
+// if available and it has the query position, for the queries that do not
+// need the whole scope program such as implements and referrers.
+// The caller must unlock the program.
+func (q *Query) cachedProgram() (*scopeProgram, *queryPos, bool) {
+	if q.Cache == nil || q.Build.OpenFile != nil || len(q.Scope) == 0 {
+		return nil, nil, false
+	}
+
+	sp, err := q.Cache.get(q)
+	if err != nil {
+		return nil, nil, false
+	}
+	qpos, err := parseQueryPos(sp.lprog, q.Pos, false)
+	if err != nil {
+		sp.unlock()
+		return nil, nil, false
+	}
+
+	return sp, qpos, true
+}
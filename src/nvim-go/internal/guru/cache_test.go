@@ -0,0 +1,198 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testCacheQuery creates the GOPATH that has the foo and bar packages, and
+// returns the query of the foo scope and the foo.go file name.
+func testCacheQuery(t *testing.T) (*Query, string) {
+	t.Setenv("GO111MODULE", "off")
+
+	gopath, err := ioutil.TempDir("", "nvim-go-guru-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(gopath) })
+
+	files := map[string]string{
+		filepath.Join("foo", "foo.go"): "package foo\n\nfunc Foo() {}\n",
+		filepath.Join("bar", "bar.go"): "package bar\n\nfunc Bar() {}\n",
+	}
+	for name, src := range files {
+		filename := filepath.Join(gopath, "src", name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	ctxt.CgoEnabled = false
+
+	return &Query{Build: &ctxt, Scope: []string{"foo"}}, filepath.Join(gopath, "src", "foo", "foo.go")
+}
+
+// cached reports whether the c has the program of q.
+func (c *Cache) cached(q *Query) (*scopeProgram, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sp, ok := c.programs[cacheKey(q)]
+	return sp, ok
+}
+
+func TestCacheGet(t *testing.T) {
+	q, foo := testCacheQuery(t)
+	c := NewCache()
+
+	sp, err := c.get(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.unlock()
+	if !sp.initial["foo"] {
+		t.Errorf("get() initial = %v, want foo", sp.initial)
+	}
+
+	sp2, err := c.get(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp2.unlock()
+	if sp2 != sp {
+		t.Errorf("get() = %p, want the cached program %p", sp2, sp)
+	}
+
+	// the file is modified outside of Neovim
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(foo, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	sp3, err := c.get(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp3.unlock()
+	if sp3 == sp {
+		t.Errorf("get() = %p, want the reloaded program of the modified file", sp3)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	q, foo := testCacheQuery(t)
+
+	type args struct {
+		filename string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantCached bool
+	}{
+		{
+			name:       "scope package file",
+			args:       args{filename: foo},
+			wantCached: false,
+		},
+		{
+			name:       "not loaded package file",
+			args:       args{filename: filepath.Join(filepath.Dir(filepath.Dir(foo)), "bar", "bar.go")},
+			wantCached: true,
+		},
+	}
+	for _, tt := range tests {
+		c := NewCache()
+		sp, err := c.get(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp.unlock()
+
+		c.Invalidate(tt.args.filename)
+		if _, ok := c.cached(q); ok != tt.wantCached {
+			t.Errorf("%s: Invalidate(%v) cached = %v, want %v", tt.name, filepath.Base(tt.args.filename), ok, tt.wantCached)
+		}
+	}
+}
+
+func TestCacheEvict(t *testing.T) {
+	q, _ := testCacheQuery(t)
+	c := NewCache()
+
+	// the different build tags are the different programs
+	queries := make([]*Query, maxCachedPrograms+1)
+	for i := range queries {
+		ctxt := *q.Build
+		ctxt.BuildTags = []string{"tag" + strconv.Itoa(i)}
+		queries[i] = &Query{Build: &ctxt, Scope: q.Scope}
+	}
+	for i, q := range queries {
+		sp, err := c.get(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp.unlock()
+		// use the first program again, the second one is the least recently used
+		if i == maxCachedPrograms-1 {
+			sp, err := c.get(queries[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sp.unlock()
+		}
+	}
+
+	if got := len(c.programs); got != maxCachedPrograms {
+		t.Errorf("len(programs) = %d, want %d", got, maxCachedPrograms)
+	}
+	for i, q := range queries {
+		if _, ok := c.cached(q); ok != (i != 1) {
+			t.Errorf("cached(tag%d) = %v, want %v", i, ok, i != 1)
+		}
+	}
+}
+
+func TestCacheReload(t *testing.T) {
+	q, foo := testCacheQuery(t)
+	c := NewCache()
+
+	sp, err := c.get(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.unlock()
+
+	c.Reload(foo)
+
+	// get waits for the in-flight reload instead of loading again
+	sp2, err := c.get(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp2.unlock()
+	if sp2 == sp {
+		t.Errorf("get() = %p, want the reloaded program", sp2)
+	}
+	if sp3, ok := c.cached(q); !ok || sp3 != sp2 {
+		t.Errorf("cached() = %p, %v, want the reloaded program %p", sp3, ok, sp2)
+	}
+	c.mu.Lock()
+	if n := len(c.reloads); n != 0 {
+		t.Errorf("len(reloads) = %d, want 0", n)
+	}
+	c.mu.Unlock()
+}
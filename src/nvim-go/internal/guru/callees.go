@@ -12,26 +12,20 @@ import (
 	"sort"
 
 	"golang.org/x/tools/cmd/guru/serial"
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/pointer"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // Callees reports the possible callees of the function call site
 // identified by the specified source location.
 func callees(q *Query) error {
-	lconf := loader.Config{Build: q.Build}
-
-	if err := setPTAScope(&lconf, q.Scope); err != nil {
-		return err
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
 	if err != nil {
 		return err
 	}
+	defer sp.unlock()
+	lprog := sp.lprog
 
 	qpos, err := parseQueryPos(lprog, q.Pos, true) // needs exact pos
 	if err != nil {
@@ -106,9 +100,9 @@ func callees(q *Query) error {
 		}
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := sp.ssaProgram(ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
 	if err != nil {
 		return err
 	}
@@ -133,7 +127,7 @@ func callees(q *Query) error {
 		return err
 	}
 
-	funcs, err := findCallees(ptaConfig, site)
+	funcs, err := findCallees(sp, ptaConfig, site)
 	if err != nil {
 		return err
 	}
@@ -154,7 +148,7 @@ func findCallSite(fn *ssa.Function, call *ast.CallExpr) (ssa.CallInstruction, er
 	return callInstr, nil
 }
 
-func findCallees(conf *pointer.Config, site ssa.CallInstruction) ([]*ssa.Function, error) {
+func findCallees(sp *scopeProgram, conf *pointer.Config, site ssa.CallInstruction) ([]*ssa.Function, error) {
 	// Avoid running the pointer analysis for static calls.
 	if callee := site.Common().StaticCallee(); callee != nil {
 		switch callee.String() {
@@ -168,9 +162,7 @@ func findCallees(conf *pointer.Config, site ssa.CallInstruction) ([]*ssa.Functio
 	}
 
 	// Dynamic call: use pointer analysis.
-	conf.BuildCallGraph = true
-	cg := ptrAnalysis(conf).CallGraph
-	cg.DeleteSyntheticNodes()
+	cg := sp.callGraph(conf)
 
 	// Find all call edges from the site.
 	n := cg.Nodes[site.Parent()]
@@ -11,7 +11,6 @@ import (
 
 	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/callgraph"
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 )
@@ -20,26 +19,22 @@ import (
 // immediately enclosing the specified source location.
 //
 func callers(q *Query) error {
-	lconf := loader.Config{Build: q.Build}
-
-	if err := setPTAScope(&lconf, q.Scope); err != nil {
-		return err
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
 	if err != nil {
 		return err
 	}
+	defer sp.unlock()
+	lprog := sp.lprog
 
 	qpos, err := parseQueryPos(lprog, q.Pos, false)
 	if err != nil {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, 0)
+	prog := sp.ssaProgram(0)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
 	if err != nil {
 		return err
 	}
@@ -68,10 +63,10 @@ func callers(q *Query) error {
 		// call found to originate from target.
 		// (Pointer analysis may return fewer results than
 		// directCallsTo because it ignores dead code.)
-		ptaConfig.BuildCallGraph = true
-		cg = ptrAnalysis(ptaConfig).CallGraph
+		cg = sp.callGraph(ptaConfig)
+	} else {
+		cg.DeleteSyntheticNodes()
 	}
-	cg.DeleteSyntheticNodes()
 	edges := cg.CreateNode(target).In
 
 	// TODO(adonovan): sort + dedup calls to ensure test determinism.
@@ -0,0 +1,64 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/static"
+)
+
+// CallGraph algorithms.
+const (
+	// CallGraphStatic only the static calls, that is the fastest.
+	CallGraphStatic = "static"
+	// CallGraphCHA the Class Hierarchy Analysis, that resolves the dynamic
+	// calls to the all methods of the compatible types.
+	CallGraphCHA = "cha"
+	// CallGraphPointer the pointer analysis, that is the most precise but needs
+	// the main or test packages in the scope.
+	CallGraphPointer = "pointer"
+)
+
+// CallGraph builds the call graph of the q.Scope program with the algo,
+// reuses the q.Cache program if set. The q.Pos is not used.
+// The synthetic nodes are deleted from the returned call graph.
+//
+// The returned call graph may be shared with the cache, so the caller must
+// not modify it.
+func CallGraph(q *Query, algo string) (*callgraph.Graph, error) {
+	sp, err := q.scopeProgram()
+	if err != nil {
+		return nil, err
+	}
+	defer sp.unlock()
+
+	prog := sp.ssaProgram(0)
+
+	switch algo {
+	case CallGraphStatic, CallGraphCHA:
+		prog.Build()
+		var cg *callgraph.Graph
+		if algo == CallGraphStatic {
+			cg = static.CallGraph(prog)
+		} else {
+			cg = cha.CallGraph(prog)
+		}
+		cg.DeleteSyntheticNodes()
+		return cg, nil
+
+	case CallGraphPointer:
+		ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
+		if err != nil {
+			return nil, err
+		}
+		prog.Build()
+		return sp.callGraph(ptaConfig), nil
+	}
+
+	return nil, fmt.Errorf("unknown call graph algorithm: %q", algo)
+}
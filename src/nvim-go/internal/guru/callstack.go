@@ -11,9 +11,7 @@ import (
 	"golang.org/x/tools/cmd/guru/serial"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/static"
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // Callstack displays an arbitrary path from a root of the callgraph
@@ -27,27 +25,23 @@ import (
 // the analysis root.
 //
 func callstack(q *Query) error {
-	fset := token.NewFileSet()
-	lconf := loader.Config{Fset: fset, Build: q.Build}
-
-	if err := setPTAScope(&lconf, q.Scope); err != nil {
-		return err
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
 	if err != nil {
 		return err
 	}
+	defer sp.unlock()
+	lprog := sp.lprog
+	fset := lprog.Fset
 
 	qpos, err := parseQueryPos(lprog, q.Pos, false)
 	if err != nil {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, 0)
+	prog := sp.ssaProgram(0)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
 	if err != nil {
 		return err
 	}
@@ -87,9 +81,7 @@ func callstack(q *Query) error {
 	// No fully static path found.
 	// Run the pointer analysis and build a complete call graph.
 	if callpath == nil {
-		ptaConfig.BuildCallGraph = true
-		cg := ptrAnalysis(ptaConfig).CallGraph
-		cg.DeleteSyntheticNodes()
+		cg := sp.callGraph(ptaConfig)
 		callpath = callgraph.PathSearch(cg.Root, isEnd)
 		if callpath != nil {
 			callpath = callpath[1:] // remove synthetic edge from <root>
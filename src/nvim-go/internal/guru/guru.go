@@ -77,6 +77,9 @@ type Query struct {
 	PTALog     io.Writer // (optional) pointer-analysis log file
 	Reflection bool      // model reflection soundly (currently slow).
 
+	// (optional) cache of the loaded scope program across the queries
+	Cache *Cache
+
 	// result-printing function
 	Output func(*token.FileSet, QueryResult)
 }
@@ -90,6 +93,8 @@ func Run(mode string, q *Query) error {
 		return callers(q)
 	case "callstack":
 		return callstack(q)
+	case "outgoing":
+		return outgoing(q)
 	case "peers":
 		return peers(q)
 	case "pointsto":
@@ -26,47 +26,20 @@ import (
 // by an implements query on the receiver type.
 //
 func implements(q *Query) error {
-	lconf := loader.Config{Build: q.Build}
-	allowErrors(&lconf)
-
-	qpkg, err := importQueryPackage(q.Pos, &lconf)
-	if err != nil {
-		return err
-	}
-
-	// Set the packages to search.
-	if len(q.Scope) > 0 {
-		// Inspect all packages in the analysis scope, if specified.
-		if err := setPTAScope(&lconf, q.Scope); err != nil {
-			return err
-		}
+	var (
+		lprog *loader.Program
+		qpos  *queryPos
+	)
+	// Reuse the cached scope program if it has the query position.
+	if sp, pos, ok := q.cachedProgram(); ok {
+		defer sp.unlock()
+		lprog, qpos = sp.lprog, pos
 	} else {
-		// Otherwise inspect the forward and reverse
-		// transitive closure of the selected package.
-		// (In theory even this is incomplete.)
-		_, rev, _ := importgraph.Build(q.Build)
-		for path := range rev.Search(qpkg) {
-			lconf.ImportWithTests(path)
+		var err error
+		lprog, qpos, err = loadImplements(q)
+		if err != nil {
+			return err
 		}
-
-		// TODO(adonovan): for completeness, we should also
-		// type-check and inspect function bodies in all
-		// imported packages.  This would be expensive, but we
-		// could optimize by skipping functions that do not
-		// contain type declarations.  This would require
-		// changing the loader's TypeCheckFuncBodies hook to
-		// provide the []*ast.File.
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := lconf.Load()
-	if err != nil {
-		return err
-	}
-
-	qpos, err := parseQueryPos(lprog, q.Pos, false)
-	if err != nil {
-		return err
 	}
 
 	// Find the selected type.
@@ -194,6 +167,55 @@ func implements(q *Query) error {
 	return nil
 }
 
+// loadImplements loads the program to search the implements relation of the
+// query position.
+func loadImplements(q *Query) (*loader.Program, *queryPos, error) {
+	lconf := loader.Config{Build: q.Build}
+	allowErrors(&lconf)
+
+	qpkg, err := importQueryPackage(q.Pos, &lconf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Set the packages to search.
+	if len(q.Scope) > 0 {
+		// Inspect all packages in the analysis scope, if specified.
+		if err := setPTAScope(&lconf, q.Scope); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// Otherwise inspect the forward and reverse
+		// transitive closure of the selected package.
+		// (In theory even this is incomplete.)
+		_, rev, _ := importgraph.Build(q.Build)
+		for path := range rev.Search(qpkg) {
+			lconf.ImportWithTests(path)
+		}
+
+		// TODO(adonovan): for completeness, we should also
+		// type-check and inspect function bodies in all
+		// imported packages.  This would be expensive, but we
+		// could optimize by skipping functions that do not
+		// contain type declarations.  This would require
+		// changing the loader's TypeCheckFuncBodies hook to
+		// provide the []*ast.File.
+	}
+
+	// Load/parse/type-check the program.
+	lprog, err := lconf.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qpos, err := parseQueryPos(lprog, q.Pos, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lprog, qpos, nil
+}
+
 type implementsResult struct {
 	qpos *queryPos
 
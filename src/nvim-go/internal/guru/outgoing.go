@@ -0,0 +1,145 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guru
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// outgoing reports the possible callees of the all call sites in the
+// function immediately enclosing the specified source location.
+// It is the reverse direction of the callers query.
+func outgoing(q *Query) error {
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
+	if err != nil {
+		return err
+	}
+	defer sp.unlock()
+	lprog := sp.lprog
+
+	qpos, err := parseQueryPos(lprog, q.Pos, false)
+	if err != nil {
+		return err
+	}
+
+	prog := sp.ssaProgram(0)
+
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
+	if err != nil {
+		return err
+	}
+
+	pkg := prog.Package(qpos.info.Pkg)
+	if pkg == nil {
+		return fmt.Errorf("no SSA package")
+	}
+	if !ssa.HasEnclosingFunction(pkg, qpos.path) {
+		return fmt.Errorf("this position is not inside a function")
+	}
+
+	// Defer SSA construction till after errors are reported.
+	prog.Build()
+
+	target := ssa.EnclosingFunction(pkg, qpos.path)
+	if target == nil {
+		return fmt.Errorf("no SSA function built for this location (dead code?)")
+	}
+
+	// The static calls are resolved without the pointer analysis.
+	// Run the pointer analysis only if the function has a dynamic call.
+	// The calls in the function literals of target are included.
+	var sites []*outgoingSite
+	var dynamic bool
+	for _, fn := range enclosedFuncs(target) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				site, ok := instr.(ssa.CallInstruction)
+				if !ok || !site.Pos().IsValid() {
+					continue
+				}
+				s := &outgoingSite{site: site}
+				if callee := site.Common().StaticCallee(); callee != nil {
+					s.callees = []*ssa.Function{callee}
+				} else {
+					dynamic = true
+				}
+				sites = append(sites, s)
+			}
+		}
+	}
+	if dynamic {
+		cg := sp.callGraph(ptaConfig)
+		for _, s := range sites {
+			if n := cg.Nodes[s.site.Parent()]; n != nil && s.callees == nil {
+				s.callees = calleesOfSite(n, s.site)
+			}
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].site.Pos() < sites[j].site.Pos() })
+
+	q.Output(lprog.Fset, &outgoingResult{
+		target: target,
+		sites:  sites,
+	})
+	return nil
+}
+
+// enclosedFuncs returns fn and its all nested function literals.
+func enclosedFuncs(fn *ssa.Function) []*ssa.Function {
+	funcs := []*ssa.Function{fn}
+	for _, anon := range fn.AnonFuncs {
+		funcs = append(funcs, enclosedFuncs(anon)...)
+	}
+	return funcs
+}
+
+// calleesOfSite returns the callees of the site in the out edges of n, sorted by the position.
+func calleesOfSite(n *callgraph.Node, site ssa.CallInstruction) []*ssa.Function {
+	seen := make(map[*ssa.Function]bool)
+	var funcs []*ssa.Function
+	for _, edge := range n.Out {
+		if edge.Site == site && !seen[edge.Callee.Func] {
+			seen[edge.Callee.Func] = true
+			funcs = append(funcs, edge.Callee.Func)
+		}
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Pos() < funcs[j].Pos() })
+
+	return funcs
+}
+
+// outgoingSite represents a call site and its possible callees.
+type outgoingSite struct {
+	site    ssa.CallInstruction
+	callees []*ssa.Function
+}
+
+type outgoingResult struct {
+	target *ssa.Function
+	sites  []*outgoingSite
+}
+
+func (r *outgoingResult) PrintPlain(printf printfFunc) {
+	if len(r.sites) == 0 {
+		printf(r.target, "%s has no call sites.", r.target)
+		return
+	}
+	printf(r.target, "%s calls from these %d sites:", r.target, len(r.sites))
+	for _, s := range r.sites {
+		for _, callee := range s.callees {
+			printf(s.site, "\t%s to %s", s.site.Common().Description(), callee)
+		}
+	}
+}
+
+func (r *outgoingResult) JSON(fset *token.FileSet) []byte {
+	return toJSON(r.Result(fset))
+}
@@ -12,7 +12,6 @@ import (
 	"sort"
 
 	"golang.org/x/tools/cmd/guru/serial"
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 )
@@ -24,26 +23,22 @@ import (
 // TODO(adonovan): permit the user to query based on a MakeChan (not send/recv),
 // or the implicit receive in "for v := range ch".
 func peers(q *Query) error {
-	lconf := loader.Config{Build: q.Build}
-
-	if err := setPTAScope(&lconf, q.Scope); err != nil {
-		return err
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
 	if err != nil {
 		return err
 	}
+	defer sp.unlock()
+	lprog := sp.lprog
 
 	qpos, err := parseQueryPos(lprog, q.Pos, false)
 	if err != nil {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := sp.ssaProgram(ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
 	if err != nil {
 		return err
 	}
@@ -16,7 +16,6 @@ import (
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/pointer"
 	"golang.org/x/tools/go/ssa"
-	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // pointsto runs the pointer analysis on the selected expression,
@@ -27,26 +26,22 @@ import (
 // All printed sets are sorted to ensure determinism.
 //
 func pointsto(q *Query) error {
-	lconf := loader.Config{Build: q.Build}
-
-	if err := setPTAScope(&lconf, q.Scope); err != nil {
-		return err
-	}
-
-	// Load/parse/type-check the program.
-	lprog, err := loadWithSoftErrors(&lconf)
+	// Load/parse/type-check the program, or reuse the cached program.
+	sp, err := q.scopeProgram()
 	if err != nil {
 		return err
 	}
+	defer sp.unlock()
+	lprog := sp.lprog
 
 	qpos, err := parseQueryPos(lprog, q.Pos, true) // needs exact pos
 	if err != nil {
 		return err
 	}
 
-	prog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
+	prog := sp.ssaProgram(ssa.GlobalDebug)
 
-	ptaConfig, err := setupPTA(prog, lprog, q.PTALog, q.Reflection)
+	ptaConfig, err := sp.setupPTA(prog, q.PTALog, q.Reflection)
 	if err != nil {
 		return err
 	}
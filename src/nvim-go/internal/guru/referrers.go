@@ -26,23 +26,34 @@ import (
 // Referrers reports all identifiers that resolve to the same object
 // as the queried identifier, within any package in the workspace.
 func referrers(q *Query) error {
-	fset := token.NewFileSet()
-	lconf := loader.Config{Fset: fset, Build: q.Build}
-	allowErrors(&lconf)
+	var (
+		fset *token.FileSet
+		qpos *queryPos
+	)
+	// Reuse the cached scope program if it has the query position.
+	sp, pos, cached := q.cachedProgram()
+	if cached {
+		defer sp.unlock()
+		fset, qpos = sp.lprog.Fset, pos
+	} else {
+		fset = token.NewFileSet()
+		lconf := loader.Config{Fset: fset, Build: q.Build}
+		allowErrors(&lconf)
 
-	if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
-		return err
-	}
+		if _, err := importQueryPackage(q.Pos, &lconf); err != nil {
+			return err
+		}
 
-	// Load/parse/type-check the query package.
-	lprog, err := lconf.Load()
-	if err != nil {
-		return err
-	}
+		// Load/parse/type-check the query package.
+		lprog, err := lconf.Load()
+		if err != nil {
+			return err
+		}
 
-	qpos, err := parseQueryPos(lprog, q.Pos, false)
-	if err != nil {
-		return err
+		qpos, err = parseQueryPos(lprog, q.Pos, false)
+		if err != nil {
+			return err
+		}
 	}
 
 	id, _ := qpos.path[0].(*ast.Ident)
@@ -76,10 +87,18 @@ func referrers(q *Query) error {
 	// of P, but for a field or interface method, we must load
 	// any package that transitively imports P.
 	if global, pkglevel := classify(obj); global {
+		defpkg := obj.Pkg().Path() // defining package
+		users := referrerUsers(q.Build, defpkg, pkglevel)
+
+		// The cached scope program already has the all packages
+		// that depend on defpkg, no need to load the larger program.
+		if cached && sp.hasPackages(users) {
+			return scopeReferrers(q, sp.lprog, obj, users)
+		}
+
 		// We'll use the the object's position to identify it in the larger program.
 		objposn := fset.Position(obj.Pos())
-		defpkg := obj.Pkg().Path() // defining package
-		return globalReferrers(q, qpos.info.Pkg.Path(), defpkg, objposn, pkglevel)
+		return globalReferrers(q, qpos.info.Pkg.Path(), defpkg, objposn, users)
 	}
 
 	q.Output(fset, &referrersInitialResult{
@@ -209,28 +228,55 @@ func outputUses(q *Query, fset *token.FileSet, refs []*ast.Ident, pkg *types.Pac
 	}
 }
 
-// globalReferrers reports references throughout the entire workspace to the
-// object at the specified source position.  Its defining package is defpkg,
-// and the query package is qpkg.  isPkgLevel indicates whether the object
-// is defined at package-level.
-func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, isPkgLevel bool) error {
+// referrerUsers returns the set of packages in the workspace that depend
+// on defpkg.  isPkgLevel indicates whether the object is defined at
+// package-level.
+func referrerUsers(ctxt *build.Context, defpkg string, isPkgLevel bool) map[string]bool {
 	// Scan the workspace and build the import graph.
 	// Ignore broken packages.
-	_, rev, _ := importgraph.Build(q.Build)
+	_, rev, _ := importgraph.Build(ctxt)
 
 	// Find the set of packages that depend on defpkg.
 	// Only function bodies in those packages need type-checking.
-	var users map[string]bool
-	if isPkgLevel {
-		users = rev[defpkg] // direct importers
-		if users == nil {
-			users = make(map[string]bool)
+	if !isPkgLevel {
+		return rev.Search(defpkg) // transitive importers
+	}
+	users := make(map[string]bool)
+	for path := range rev[defpkg] {
+		users[path] = true // direct importers
+	}
+	users[defpkg] = true // plus the defining package itself
+
+	return users
+}
+
+// scopeReferrers reports references to obj in the users packages of the
+// loaded scope program lprog.
+func scopeReferrers(q *Query, lprog *loader.Program, obj types.Object, users map[string]bool) error {
+	var infos []*loader.PackageInfo
+	for _, info := range lprog.AllPackages {
+		if users[strings.TrimSuffix(info.Pkg.Path(), "_test")] {
+			infos = append(infos, info)
 		}
-		users[defpkg] = true // plus the defining package itself
-	} else {
-		users = rev.Search(defpkg) // transitive importers
 	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Pkg.Path() < infos[j].Pkg.Path() })
 
+	q.Output(lprog.Fset, &referrersInitialResult{
+		qinfo: lprog.AllPackages[obj.Pkg()],
+		obj:   obj,
+	})
+	for _, info := range infos {
+		outputUses(q, lprog.Fset, usesOf(obj, info), info.Pkg)
+	}
+
+	return nil // success
+}
+
+// globalReferrers reports references throughout the entire workspace to the
+// object at the specified source position.  Its defining package is defpkg,
+// and the query package is qpkg.  users is the set of packages that depend
+// on defpkg.
+func globalReferrers(q *Query, qpkg, defpkg string, objposn token.Position, users map[string]bool) error {
 	// Prepare to load the larger program.
 	fset := token.NewFileSet()
 	lconf := loader.Config{
@@ -46,6 +46,9 @@ func (r *calleesTypesResult) Result(fset *token.FileSet) interface{} {
 func (r *callersResult) Result(fset *token.FileSet) interface{} {
 	var callers []serial.Caller
 	for _, edge := range r.edges {
+		if edge.Caller.Func == nil {
+			continue // the root of the call graph
+		}
 		callers = append(callers, serial.Caller{
 			Caller: edge.Caller.Func.String(),
 			Pos:    fset.Position(edge.Pos()).String(),
@@ -55,6 +58,26 @@ func (r *callersResult) Result(fset *token.FileSet) interface{} {
 	return callers
 }
 
+// outgoing
+func (r *outgoingResult) Result(fset *token.FileSet) interface{} {
+	var callees []*serial.Callees
+	for _, s := range r.sites {
+		var items []*serial.Callee
+		for _, callee := range s.callees {
+			items = append(items, &serial.Callee{
+				Name: callee.String(),
+				Pos:  fset.Position(callee.Pos()).String(),
+			})
+		}
+		callees = append(callees, &serial.Callees{
+			Pos:     fset.Position(s.site.Pos()).String(),
+			Desc:    s.site.Common().Description(),
+			Callees: items,
+		})
+	}
+	return callees
+}
+
 // callstack
 func (r *callstackResult) Result(fset *token.FileSet) interface{} {
 	var callers []serial.Caller
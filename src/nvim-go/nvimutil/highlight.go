@@ -0,0 +1,26 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nvimutil
+
+import (
+	"github.com/neovim/go-client/nvim"
+	"github.com/pkg/errors"
+)
+
+// CreateNamespace creates the new highlight namespace of name, or returns the
+// existing namespace id if already created.
+func CreateNamespace(v *nvim.Nvim, name string) (int, error) {
+	var ns int
+	if err := v.Call("nvim_create_namespace", &ns, name); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return ns, nil
+}
+
+// ClearNamespace clears the all highlights and virtual texts of the ns namespace in the buffer.
+func ClearNamespace(v *nvim.Nvim, b nvim.Buffer, ns int) error {
+	return errors.WithStack(v.Call("nvim_buf_clear_namespace", nil, int(b), ns, 0, -1))
+}
@@ -250,6 +250,15 @@ func ParseError(errs []byte, cwd string, buildContext *ctx.Build, ignoreDirs []s
 				sep = pathutil.TrimGoPath(cwd) + string(filepath.Separator)
 				filename = strings.TrimPrefix(filename, sep)
 			}
+		case "mod":
+			// filename is like "example.com/foo/bar.go" if contains '#' package title in error
+			if !filepath.IsAbs(filename) {
+				if dir, ok := pathutil.ModuleDir(buildContext.ProjectRoot, filepath.Dir(filename)); ok {
+					filename = filepath.Join(dir, filepath.Base(filename))
+				} else {
+					filename = filepath.Join(cwd, filename)
+				}
+			}
 		case "gb":
 			// gb compiler error messages is relative filename path of project root dir
 			if !filepath.IsAbs(filename) {
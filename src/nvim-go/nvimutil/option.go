@@ -91,4 +91,18 @@ const (
 	FiletypeTerminal = "terminal"
 	// FiletypeGoTerminal represents a go-terminal filetype.
 	FiletypeGoTerminal = "go-terminal"
+	// FiletypeGoDefStack represents a go-defstack filetype.
+	FiletypeGoDefStack = "go-defstack"
+	// FiletypeGoCover represents a go-cover filetype.
+	FiletypeGoCover = "go-cover"
+	// FiletypeGoBench represents a go-bench filetype.
+	FiletypeGoBench = "go-bench"
+	// FiletypeGoTest represents a go-test filetype.
+	FiletypeGoTest = "go-test"
+	// FiletypeGoCallHierarchy represents a go-callhierarchy filetype.
+	FiletypeGoCallHierarchy = "go-callhierarchy"
+	// FiletypeGoCallgraph represents a go-callgraph filetype.
+	FiletypeGoCallgraph = "go-callgraph"
+	// FiletypeGoDecls represents a go-decls filetype.
+	FiletypeGoDecls = "go-decls"
 )
@@ -43,6 +43,14 @@ const (
 	// RestartSymbol symbol of restart.
 	// ⟲  ANTICLOCKWISE GAPPED CIRCLE ARROW    (U+27F2)
 	RestartSymbol = "\u27f2"
+	// PassSymbol symbol of passed test.
+	//
+	// ✔  HEAVY CHECK MARK                     (U+2714)
+	PassSymbol = "\u2714"
+	// FailSymbol symbol of failed test.
+	//
+	// ✖  HEAVY MULTIPLICATION X               (U+2716)
+	FailSymbol = "\u2716"
 )
 
 // Sign represents a Neovim sign.
@@ -25,6 +25,8 @@ type Terminal struct {
 	Name string
 	// Dir specifies the working directory of the command on terminal.
 	Dir string
+	// Env specifies the additional environment of the command such as "GOOS=linux".
+	Env []string
 	// Size open the terminal window size.
 	Size int
 
@@ -70,7 +72,7 @@ func (t *Terminal) Create() (err error) {
 	}
 
 	option := t.setTerminalOption()
-	name := fmt.Sprintf("| terminal %s", strings.Join(t.cmd, " "))
+	name := fmt.Sprintf("| terminal %s", strings.Join(t.command(t.cmd), " "))
 	mode := fmt.Sprintf("%s %d%s", config.TerminalPosition, t.Size, t.mode)
 
 	t.Buffer.Create(name, FiletypeTerminal, mode, option)
@@ -101,7 +103,7 @@ func (t *Terminal) Run(cmd []string) error {
 		defer t.switchFocus()()
 
 		t.Nvim.SetBufferOption(t.buffer, BufOptionModified, false)
-		t.Nvim.Call("termopen", nil, cmd)
+		t.Nvim.Call("termopen", nil, t.command(cmd))
 		t.Nvim.SetBufferName(t.buffer, t.Buffer.Name)
 	} else {
 		t.Create()
@@ -119,6 +121,14 @@ func (t *Terminal) Run(cmd []string) error {
 	return errors.WithStack(t.Nvim.SetWindowCursor(t.Window, [2]int{lines, 0}))
 }
 
+// command returns the cmd with the env command prefix if t.Env is set.
+func (t *Terminal) command(cmd []string) []string {
+	if len(t.Env) == 0 {
+		return cmd
+	}
+	return append(append([]string{"env"}, t.Env...), cmd...)
+}
+
 // getSplitWindowSize return the one third of window (height|width) size if cfg is 0
 func (t *Terminal) getSplitWindowSize(cfg int64, f func(nvim.Window) (int, error)) int {
 	if cfg == 0 {
@@ -15,6 +15,9 @@ import (
 func parsePackage(dir string) (*build.Package, error) {
 	dir = filepath.Clean(dir)
 
+	// Do not search above the module root if dir is under the Go module
+	modRoot, isModule := IsModule(dir)
+
 	// for save the before(child) package information
 	savePkg := new(build.Package)
 	for {
@@ -22,6 +25,13 @@ func parsePackage(dir string) (*build.Package, error) {
 		if dir == "/" || dir == build.Default.GOPATH || dir == build.Default.GOROOT {
 			return nil, errors.New("couldn't find the package")
 		}
+		// Use child(before) package if dir is reaches the parent of module root
+		if isModule && len(dir) < len(modRoot) {
+			if savePkg.Dir != "" {
+				return savePkg, nil
+			}
+			return nil, errors.New("couldn't find the package")
+		}
 
 		// Get the current dir package information
 		pkg, err := build.Default.ImportDir(dir, build.ImportMode(0))
@@ -73,6 +83,7 @@ func PackagePath(dir string) (string, error) {
 
 // PackageID returns the package ID(ImportPath) estimated from the dir
 // directory structure.
+// If dir is under the Go module, the package ID is derived from the module path.
 // like:
 //  return "github.com/pkg/errors", nil
 func PackageID(dir string) (string, error) {
@@ -81,5 +92,9 @@ func PackageID(dir string) (string, error) {
 		return "", err
 	}
 
+	if modRoot, ok := IsModule(pkg.Dir); ok {
+		return ModuleImportPath(modRoot, pkg.Dir)
+	}
+
 	return pkg.ImportPath, nil
 }
@@ -132,6 +132,18 @@ func TestPackageID(t *testing.T) {
 			want:    filepath.Join("foo.org", "foo", "bar", "baz", "qux"),
 			wantErr: false,
 		},
+		{
+			name:    "module root package",
+			args:    args{dir: filepath.Join(testModPath, "foo")},
+			want:    "foo.org/foo",
+			wantErr: false,
+		},
+		{
+			name:    "module sub package",
+			args:    args{dir: filepath.Join(testModPath, "foo", "bar")},
+			want:    "foo.org/foo/bar",
+			wantErr: false,
+		},
 		{
 			name:    "no such file or directory",
 			args:    args{dir: filepath.Join("nosuch", "src", "foo.org", "notexists")},
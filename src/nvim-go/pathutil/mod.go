@@ -0,0 +1,120 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GoModFile is the name of the Go module definition file.
+const GoModFile = "go.mod"
+
+// IsModule check the dir whether the under the Go module.
+// Return the module root path and boolean.
+func IsModule(dir string) (string, bool) {
+	root, err := FindModuleRoot(dir)
+	if err != nil {
+		return "", false
+	}
+	return root, true
+}
+
+// FindModuleRoot works upwards from dir searching for the go.mod file which
+// identifies the module root.
+func FindModuleRoot(dir string) (string, error) {
+	if dir == "" {
+		return "", errors.New("module root is blank")
+	}
+	start := dir
+	dir = filepath.Clean(dir)
+	for {
+		if IsExist(filepath.Join(dir, GoModFile)) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf(`could not find %s in "%s" or its parents`, GoModFile, start)
+}
+
+// ModulePath returns the module path declared by the module directive of the
+// go.mod file in the root directory, such as "github.com/pkg/errors".
+func ModulePath(root string) (string, error) {
+	gomod := filepath.Join(root, GoModFile)
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read %s", gomod)
+	}
+
+	modPath := parseModulePath(data)
+	if modPath == "" {
+		return "", errors.Errorf("could not find module directive in %s", gomod)
+	}
+	return modPath, nil
+}
+
+// parseModulePath parses the module path from the go.mod file data.
+func parseModulePath(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		// the module keyword must be followed by the whitespace such as "module foo"
+		f := strings.Fields(line)
+		if len(f) != 2 || f[0] != "module" {
+			continue
+		}
+		return unquote(f[1])
+	}
+	return ""
+}
+
+// ModuleImportPath returns the import path of the dir package directory
+// derived from the module path of the module root directory.
+func ModuleImportPath(root, dir string) (string, error) {
+	modPath, err := ModulePath(root)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s is not under the module root %s", dir, root)
+	}
+	if rel == "." {
+		return modPath, nil
+	}
+	return modPath + "/" + filepath.ToSlash(rel), nil
+}
+
+// ModuleDir returns the directory full path of the importPath package that
+// belongs to the module of the root directory.
+func ModuleDir(root, importPath string) (string, bool) {
+	modPath, err := ModulePath(root)
+	if err != nil {
+		return "", false
+	}
+
+	importPath = filepath.ToSlash(importPath)
+	switch {
+	case importPath == modPath:
+		return root, true
+	case strings.HasPrefix(importPath, modPath+"/"):
+		return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(importPath, modPath+"/"))), true
+	}
+	return "", false
+}
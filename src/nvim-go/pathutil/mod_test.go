@@ -0,0 +1,223 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"nvim-go/pathutil"
+)
+
+var testModPath = filepath.Join(testCwd, "testdata", "mod")
+
+func TestFindModuleRoot(t *testing.T) {
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module root",
+			args:    args{dir: filepath.Join(testModPath, "foo")},
+			want:    filepath.Join(testModPath, "foo"),
+			wantErr: false,
+		},
+		{
+			name:    "module sub package",
+			args:    args{dir: filepath.Join(testModPath, "foo", "bar")},
+			want:    filepath.Join(testModPath, "foo"),
+			wantErr: false,
+		},
+		{
+			name:    "not module",
+			args:    args{dir: filepath.Join(testCwd, "testdata", "go", "src", "foo.org", "foo")},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "empty path",
+			args:    args{dir: ""},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := pathutil.FindModuleRoot(tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindModuleRoot(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FindModuleRoot(%v) = %v, want %v", tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	type args struct {
+		root string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "foo.org/foo",
+			args:    args{root: filepath.Join(testModPath, "foo")},
+			want:    "foo.org/foo",
+			wantErr: false,
+		},
+		{
+			name:    "quoted module path with comment",
+			args:    args{root: filepath.Join(testModPath, "quoted")},
+			want:    "foo.org/quoted",
+			wantErr: false,
+		},
+		{
+			name:    "module prefix without the whitespace",
+			args:    args{root: filepath.Join(testModPath, "prefix")},
+			want:    "foo.org/prefix",
+			wantErr: false,
+		},
+		{
+			name:    "no module directive",
+			args:    args{root: filepath.Join(testModPath, "nomodule")},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "no go.mod file",
+			args:    args{root: testModPath},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := pathutil.ModulePath(tt.args.root)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModulePath(%v) error = %v, wantErr %v", tt.args.root, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModulePath(%v) = %v, want %v", tt.args.root, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleImportPath(t *testing.T) {
+	root := filepath.Join(testModPath, "foo")
+
+	type args struct {
+		root string
+		dir  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "module root",
+			args:    args{root: root, dir: root},
+			want:    "foo.org/foo",
+			wantErr: false,
+		},
+		{
+			name:    "sub package",
+			args:    args{root: root, dir: filepath.Join(root, "bar")},
+			want:    "foo.org/foo/bar",
+			wantErr: false,
+		},
+		{
+			name:    "outside of module root",
+			args:    args{root: root, dir: testModPath},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := pathutil.ModuleImportPath(tt.args.root, tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModuleImportPath(%v, %v) error = %v, wantErr %v", tt.args.root, tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModuleImportPath(%v, %v) = %v, want %v", tt.args.root, tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleDir(t *testing.T) {
+	root := filepath.Join(testModPath, "foo")
+
+	type args struct {
+		root       string
+		importPath string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  string
+		want1 bool
+	}{
+		{
+			name:  "module path",
+			args:  args{root: root, importPath: "foo.org/foo"},
+			want:  root,
+			want1: true,
+		},
+		{
+			name:  "sub package",
+			args:  args{root: root, importPath: "foo.org/foo/bar"},
+			want:  filepath.Join(root, "bar"),
+			want1: true,
+		},
+		{
+			name:  "similar prefix",
+			args:  args{root: root, importPath: "foo.org/foobar"},
+			want:  "",
+			want1: false,
+		},
+		{
+			name:  "other module",
+			args:  args{root: root, importPath: "github.com/pkg/errors"},
+			want:  "",
+			want1: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, got1 := pathutil.ModuleDir(tt.args.root, tt.args.importPath)
+			if got != tt.want {
+				t.Errorf("ModuleDir(%v, %v) got = %v, want %v", tt.args.root, tt.args.importPath, got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("ModuleDir(%v, %v) got1 = %v, want %v", tt.args.root, tt.args.importPath, got1, tt.want1)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ type FindMode int
 
 const (
 	ModeExcludeVendor FindMode = 1 << iota
+	// ModeExcludeModule excludes the nested module directory trees that have their own go.mod file.
+	ModeExcludeModule
 )
 
 // FindAllPackage returns a list of all packages in all of the GOPATH trees
@@ -36,6 +38,9 @@ func FindAllPackage(root string, buildContext build.Context, ignores []string, m
 		if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || (mode&ModeExcludeVendor != 0 && elem == "vendor") || matchIgnore(elem, ignores) {
 			return filepath.SkipDir
 		}
+		if mode&ModeExcludeModule != 0 && path != root && IsExist(filepath.Join(path, GoModFile)) {
+			return filepath.SkipDir
+		}
 
 		name := filepath.ToSlash(path[len(root):])
 		if done[name] {
@@ -0,0 +1,3 @@
+module foo.org/foo
+
+go 1.27.1
@@ -0,0 +1 @@
+require github.com/pkg/errors v0.8.0
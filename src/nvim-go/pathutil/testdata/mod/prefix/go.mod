@@ -0,0 +1,4 @@
+// the directive that only has the module prefix
+modulex foo.org/bad
+
+module	foo.org/prefix
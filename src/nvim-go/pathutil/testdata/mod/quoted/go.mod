@@ -0,0 +1,4 @@
+// quoted module path with the trailing comment
+module "foo.org/quoted" // comment
+
+require github.com/pkg/errors v0.8.0
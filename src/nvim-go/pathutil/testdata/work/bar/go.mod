@@ -0,0 +1 @@
+module foo.org/bar
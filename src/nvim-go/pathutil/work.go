@@ -0,0 +1,143 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GoWorkFile is the name of the Go workspace definition file.
+const GoWorkFile = "go.work"
+
+// IsWorkspace check the dir whether the under the Go workspace.
+// Return the workspace root path and boolean.
+func IsWorkspace(dir string) (string, bool) {
+	root, err := FindWorkspaceRoot(dir)
+	if err != nil {
+		return "", false
+	}
+	return root, true
+}
+
+// FindWorkspaceRoot works upwards from dir searching for the go.work file
+// which identifies the workspace root.
+// Like the go tool, $GOWORK overrides the search, and "off" disables the workspace.
+func FindWorkspaceRoot(dir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); {
+	case gowork == "off":
+		return "", errors.New("workspace mode is disabled by GOWORK=off")
+	case gowork != "":
+		if !filepath.IsAbs(gowork) || IsNotExist(gowork) {
+			return "", errors.Errorf("invalid GOWORK value: %s", gowork)
+		}
+		return filepath.Dir(gowork), nil
+	}
+
+	if dir == "" {
+		return "", errors.New("workspace root is blank")
+	}
+	start := dir
+	dir = filepath.Clean(dir)
+	for {
+		if IsExist(filepath.Join(dir, GoWorkFile)) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf(`could not find %s in "%s" or its parents`, GoWorkFile, start)
+}
+
+// WorkspaceModules returns the full path of module root directories declared
+// by the use directives of the go.work file in the root directory.
+func WorkspaceModules(root string) ([]string, error) {
+	gowork := filepath.Join(root, GoWorkFile)
+	data, err := ioutil.ReadFile(gowork)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", gowork)
+	}
+
+	var modules []string
+	for _, use := range parseWorkUse(data) {
+		if !filepath.IsAbs(use) {
+			use = filepath.Join(root, filepath.FromSlash(use))
+		}
+		modules = append(modules, filepath.Clean(use))
+	}
+	if len(modules) == 0 {
+		return nil, errors.Errorf("could not find use directive in %s", gowork)
+	}
+
+	return modules, nil
+}
+
+// parseWorkUse parses the directory paths of use directives from the go.work file data.
+// Both of the single line and block form are supported.
+func parseWorkUse(data []byte) []string {
+	var (
+		uses  []string
+		block bool
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		if block {
+			if line == ")" {
+				block = false
+				continue
+			}
+			uses = append(uses, unquote(line))
+			continue
+		}
+
+		// the use keyword must be followed by the whitespace or the block
+		// such as "use ./foo" or "use(", not "usex" or "user/foo"
+		if strings.HasPrefix(line, "use(") {
+			line = "use " + line[len("use"):]
+		}
+		if f := strings.Fields(line); len(f) < 2 || f[0] != "use" {
+			continue
+		}
+		line = strings.TrimSpace(line[len("use"):])
+		switch line {
+		case "(":
+			block = true
+		default:
+			uses = append(uses, unquote(line))
+		}
+	}
+
+	return uses
+}
+
+// unquote returns the unquoted s if quoted, otherwise returns s.
+func unquote(s string) string {
+	if len(s) > 0 && (s[0] == '"' || s[0] == '`') {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+// Copyright 2017 The nvim-go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pathutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nvim-go/pathutil"
+)
+
+var testWorkPath = filepath.Join(testCwd, "testdata", "work")
+
+func TestFindWorkspaceRoot(t *testing.T) {
+	type args struct {
+		dir    string
+		gowork string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "workspace root",
+			args:    args{dir: testWorkPath},
+			want:    testWorkPath,
+			wantErr: false,
+		},
+		{
+			name:    "member module sub package",
+			args:    args{dir: filepath.Join(testWorkPath, "foo", "sub")},
+			want:    testWorkPath,
+			wantErr: false,
+		},
+		{
+			name:    "not workspace",
+			args:    args{dir: testModPath},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "GOWORK=off",
+			args:    args{dir: testWorkPath, gowork: "off"},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "GOWORK file path",
+			args:    args{dir: testModPath, gowork: filepath.Join(testWorkPath, "go.work")},
+			want:    testWorkPath,
+			wantErr: false,
+		},
+		{
+			name:    "GOWORK relative path",
+			args:    args{dir: testWorkPath, gowork: "go.work"},
+			want:    "",
+			wantErr: true,
+		},
+	}
+
+	// $GOWORK is the process global state, so don't run the sub tests in parallel
+	defer os.Setenv("GOWORK", os.Getenv("GOWORK"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("GOWORK", tt.args.gowork)
+			got, err := pathutil.FindWorkspaceRoot(tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindWorkspaceRoot(%v) error = %v, wantErr %v", tt.args.dir, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FindWorkspaceRoot(%v) = %v, want %v", tt.args.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkspaceModules(t *testing.T) {
+	type args struct {
+		root string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single line and block use directives",
+			args: args{root: testWorkPath},
+			want: []string{
+				filepath.Join(testWorkPath, "foo"),
+				filepath.Join(testWorkPath, "bar"),
+				filepath.Join(testWorkPath, "baz"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "use prefix without the whitespace",
+			args: args{root: filepath.Join(testWorkPath, "prefix")},
+			want: []string{
+				filepath.Join(testWorkPath, "prefix", "foo"),
+				filepath.Join(testWorkPath, "prefix", "bar"),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no use directive",
+			args:    args{root: filepath.Join(testWorkPath, "nouse")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "no go.work file",
+			args:    args{root: testModPath},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := pathutil.WorkspaceModules(tt.args.root)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WorkspaceModules(%v) error = %v, wantErr %v", tt.args.root, err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WorkspaceModules(%v) = %v, want %v", tt.args.root, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+syn match GoCallgraphSummary  /\%1l^GoCallgraph:.*$/
+syn match GoCallgraphDot      /\%2l^DOT:.*$/
+syn match GoCallgraphMore     /\s\.\.\.\ze\s/
+syn match GoCallgraphPos      /\S\+:\d\+:\d\+$/
+
+hi def link GoCallgraphSummary  Statement
+hi def link GoCallgraphDot      Comment
+hi def link GoCallgraphMore     Special
+hi def link GoCallgraphPos      Directory
@@ -0,0 +1,7 @@
+syn match GoCallHierarchySummary  /\%1l^GoCallHierarchy:.*$/
+syn match GoCallHierarchyMarker   /^\s*[-+]/
+syn match GoCallHierarchyPos      /\S\+:\d\+:\d\+$/
+
+hi def link GoCallHierarchySummary  Statement
+hi def link GoCallHierarchyMarker   Special
+hi def link GoCallHierarchyPos      Directory
@@ -0,0 +1,7 @@
+syn match GoCoverReportSummary  /\%1l^GoCoverReport:.*$/
+syn match GoCoverReportPercent  /^\s*\d\+\.\d%/
+syn match GoCoverReportLine     /:\d\+$/
+
+hi def link GoCoverReportSummary  Statement
+hi def link GoCoverReportPercent  Number
+hi def link GoCoverReportLine     Comment
@@ -0,0 +1,7 @@
+syn match GoDeclsSummary  /\%1l^GoDecls\(Dir\)\?:.*$/
+syn match GoDeclsKind     /^\(func\|method\|type\|const\|var\)\>/
+syn match GoDeclsPos      /\S\+:\d\+:\d\+$/
+
+hi def link GoDeclsSummary  Statement
+hi def link GoDeclsKind     Keyword
+hi def link GoDeclsPos      Directory
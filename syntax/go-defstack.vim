@@ -0,0 +1,9 @@
+syn match GoDefStackSummary  /\%1l^GoDefStack:.*$/
+syn match GoDefStackCurrent  /^>/
+syn match GoDefStackNumber   /^. \s*\d\+/ contains=GoDefStackCurrent
+syn match GoDefStackPos      /\S\+:\d\+:\d\+/
+
+hi def link GoDefStackSummary  Statement
+hi def link GoDefStackCurrent  Debug
+hi def link GoDefStackNumber   Number
+hi def link GoDefStackPos      Directory
@@ -0,0 +1,15 @@
+syn match GoTestResultsSummary  /\%1l^GoTest:.*$/
+syn match GoTestResultsFold     /[▼▶]/
+syn match GoTestResultsPass     /✔/
+syn match GoTestResultsFail     /✖/
+syn match GoTestResultsSkip     /↷/
+syn match GoTestResultsElapsed  /(\d\+\.\d\+s)$/
+syn match GoTestResultsOutput   /│.*$/
+
+hi def link GoTestResultsSummary  Statement
+hi def link GoTestResultsFold     Debug
+hi def link GoTestResultsPass     GoTestPassSign
+hi def link GoTestResultsFail     GoTestFailSign
+hi def link GoTestResultsSkip     Comment
+hi def link GoTestResultsElapsed  Number
+hi def link GoTestResultsOutput   Comment